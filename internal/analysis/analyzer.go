@@ -6,14 +6,26 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 )
 
 type DailySummary struct {
 	Date          string              `json:"date"`
+	Profile       string              `json:"profile,omitempty"` // 生成摘要所用的范围配置名称
+	GeneratedAt   time.Time           `json:"generated_at"`
 	TotalPapers   int                 `json:"total_papers"`
 	TopTopics     []TopicCount        `json:"top_topics"`
 	Breakthroughs []PaperWithOneLiner `json:"breakthroughs"`
 	MajorTrends   []string            `json:"major_trends"`
+	// Trends 为相对此前 30 天基线的上升与下降主题，需要本地论文库
+	Trends *TrendReport `json:"trends,omitempty"`
+	// Institutions 为论文数最多的机构，NotablePapers 为作者来自重点机构的论文
+	Institutions  []InstitutionCount  `json:"institutions,omitempty"`
+	NotablePapers []PaperWithOneLiner `json:"notable_papers,omitempty"`
+	// Sources 记录生成摘要时各数据源的执行情况
+	Sources []model.SourceStatus `json:"sources"`
+	// Enrichment 记录补全阶段（如 enrich=s2）的执行情况
+	Enrichment []model.SourceStatus `json:"enrichment,omitempty"`
 }
 
 type PaperWithOneLiner struct {
@@ -22,17 +34,26 @@ type PaperWithOneLiner struct {
 }
 
 type TopicCount struct {
-	Topic string `json:"topic"`
-	Count int    `json:"count"`
+	Topic    string   `json:"topic"`
+	Count    int      `json:"count"`              // 出现该主题的论文数
+	Score    float64  `json:"score"`              // TF-IDF 得分，用于排序
+	Examples []string `json:"examples,omitempty"` // 示例论文 ID
 }
 
-// 需要高亮的重点机构（简化列表）
+// 需要高亮的重点机构（简化列表）：名称片段按完整单词匹配机构名称，也可以是 ROR ID。
+// 可通过摘要配置的 institutions 替换
 var TopInstitutions = []string{
-	"MIT", "Stanford", "Berkeley", "Carnegie Mellon", "CMU",
+	"MIT", "Massachusetts Institute of Technology", "Stanford", "Berkeley", "Carnegie Mellon", "CMU",
 	"Google", "DeepMind", "Meta", "Facebook", "Microsoft",
 	"Tsinghua", "Peking", "ETH", "Oxford", "Cambridge",
 }
 
+// Options 是 AnalyzePapers 的可选参数
+type Options struct {
+	Background   *Corpus  // 主题提取的背景语料（通常是之前 30 天的论文），为空时以当天论文自身为背景
+	Institutions []string // 重点机构，为空时使用 TopInstitutions
+}
+
 // 指示潜在突破或趋势的关键字
 // 将关键字映射到显示名称
 var TrendKeywords = map[string]string{
@@ -56,25 +77,30 @@ var TrendKeywords = map[string]string{
 	"Code Generation":        "Code Generation",
 }
 
-// 从主题提取中排除的停用词
+// 主题提取中的停用词，短语不以停用词开头或结尾
 var StopWords = map[string]bool{
 	"for": true, "and": true, "the": true, "with": true, "via": true,
 	"of": true, "in": true, "a": true, "an": true, "using": true,
 	"based": true, "to": true, "on": true, "from": true, "by": true,
-	"approach": true, "method": true, "system": true, "analysis": true,
-	"learning": true, "network": true, "model": true, // Too generic
-	"large": true, "new": true, "novel": true, "study": true,
-	"survey": true, "review": true, "performance": true,
-	"proposed": true, "propose": true,
+	"new": true, "novel": true, "proposed": true, "propose": true,
+	"we": true, "our": true, "this": true, "that": true, "these": true,
+	"is": true, "are": true, "be": true, "can": true, "which": true,
+	"it": true, "its": true, "their": true, "as": true, "at": true,
+	"or": true, "not": true, "how": true, "when": true, "what": true,
+	"into": true, "through": true, "towards": true, "under": true,
+	"between": true, "across": true, "beyond": true, "without": true,
+	"than": true, "such": true, "also": true, "while": true, "has": true,
+	"have": true, "been": true, "more": true, "most": true, "do": true,
+	"does": true, "show": true, "present": true, "introduce": true,
 }
 
-func AnalyzePapers(papers []model.Paper, date string) DailySummary {
+// AnalyzePapers 生成每日摘要
+func AnalyzePapers(papers []model.Paper, date string, opts Options) DailySummary {
 	summary := DailySummary{
 		Date:        date,
 		TotalPapers: len(papers),
 	}
 
-	topicFreq := make(map[string]int)
 	breakthroughs := make([]PaperWithOneLiner, 0)
 
 	// 按趋势关键字对论文进行分组，以找到特定的代表性论文
@@ -85,25 +111,10 @@ func AnalyzePapers(papers []model.Paper, date string) DailySummary {
 		abstractLower := strings.ToLower(p.Abstract)
 		combined := titleLower + " " + abstractLower
 
-		// 1. 主题提取（基于标题的简单词频）
-		words := strings.Fields(titleLower)
-		for _, w := range words {
-			// 清除标点符号
-			w = strings.Trim(w, ":,.-()[]\"'")
-			if len(w) < 3 {
-				continue
-			}
-			if !StopWords[w] {
-				topicFreq[w]++
-			}
-		}
-
-		// 同时检查特定的趋势关键字
+		// 1. 按趋势关键字分组（主题提取见 ExtractTopics）
 		for kw, display := range TrendKeywords {
-			if strings.Contains(combined, strings.ToLower(kw)) {
+			if containsPhrase(combined, strings.ToLower(kw)) {
 				trendGroups[display] = append(trendGroups[display], p)
-				// 也将这些已知概念计为主题（权重更高？）
-				topicFreq[display]++
 			}
 		}
 
@@ -125,7 +136,7 @@ func AnalyzePapers(papers []model.Paper, date string) DailySummary {
 
 		if isHighlight {
 			// 提取一句话简介
-			oneLiner := extractOneLiner(p.Abstract)
+			oneLiner := oneLinerOf(p)
 			breakthroughs = append(breakthroughs, PaperWithOneLiner{
 				Paper:    p,
 				OneLiner: oneLiner,
@@ -133,20 +144,15 @@ func AnalyzePapers(papers []model.Paper, date string) DailySummary {
 		}
 	}
 
-	// 排序并挑选热门主题
-	topics := make([]TopicCount, 0)
-	for k, v := range topicFreq {
-		if v > 1 { // 阈值
-			topics = append(topics, TopicCount{Topic: k, Count: v})
-		}
-	}
-	sort.Slice(topics, func(i, j int) bool {
-		return topics[i].Count > topics[j].Count
-	})
-	if len(topics) > 10 {
-		topics = topics[:10]
+	// 热门主题：TF-IDF 关键短语
+	summary.TopTopics = ExtractTopics(papers, opts.Background, maxTopics)
+
+	// 机构统计与重点机构论文
+	notable := opts.Institutions
+	if len(notable) == 0 {
+		notable = TopInstitutions
 	}
-	summary.TopTopics = topics
+	summary.Institutions, summary.NotablePapers = analyzeInstitutions(papers, notable)
 
 	// 限制突破数量
 	// 优先 CCF A 或高引用（如果有）或仅按列表顺序
@@ -205,7 +211,7 @@ func AnalyzePapers(papers []model.Paper, date string) DailySummary {
 
 		// 构建叙述
 		// 例如 "<b>Large Language Model</b>: 5 篇相关论文。其中 <i>'Title'</i> 提出了..."
-		oneLiner := extractOneLiner(repPaper.Abstract)
+		oneLiner := oneLinerOf(repPaper)
 		// 应要求移除激进的截断以提供更多细节
 		// if len(oneLiner) > 50 {
 		// 	oneLiner = oneLiner[:50] + "..."
@@ -222,6 +228,14 @@ func AnalyzePapers(papers []model.Paper, date string) DailySummary {
 	return summary
 }
 
+// oneLinerOf 返回论文的一句话简介：优先使用 S2 的 TLDR，否则从摘要中提取
+func oneLinerOf(p model.Paper) string {
+	if p.TLDR != "" {
+		return p.TLDR
+	}
+	return extractOneLiner(p.Abstract)
+}
+
 // extractOneLiner 尝试找到“贡献”部分并返回重要片段
 func extractOneLiner(abstract string) string {
 	// 1. 尝试找到开始描述贡献的关键短语
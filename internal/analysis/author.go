@@ -0,0 +1,194 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+)
+
+const (
+	// maxAuthorVenues 是作者档案列出的发表渠道数
+	maxAuthorVenues = 10
+	// maxCoAuthors 是作者档案列出的合作者数
+	maxCoAuthors = 10
+)
+
+// YearCount 是作者某一年的论文数与这些论文的引用量
+type YearCount struct {
+	Year      int `json:"year"`
+	Papers    int `json:"papers"`
+	Citations int `json:"citations"`
+}
+
+// VenueCount 是作者在某个会议或期刊发表的论文数
+type VenueCount struct {
+	Venue    string `json:"venue"`
+	CCFClass string `json:"ccf_class"`
+	Count    int    `json:"count"`
+}
+
+// CoAuthor 是与作者合作的论文数
+type CoAuthor struct {
+	Author model.Author `json:"author"`
+	Count  int          `json:"count"`
+}
+
+// AuthorStats 是基于作者论文列表计算的统计
+type AuthorStats struct {
+	Papers    int          `json:"paper_count"`
+	Citations int          `json:"citations"`
+	HIndex    int          `json:"h_index"`
+	Years     []YearCount  `json:"years"` // 按年份升序
+	Venues    []VenueCount `json:"venues"`
+	CoAuthors []CoAuthor   `json:"co_authors"`
+}
+
+// AnalyzeAuthor 统计 author 的论文：总引用量、h 指数、逐年产出、主要发表渠道（含 CCF 分级）与常见合作者。
+// 统计只覆盖传入的论文。合作者按标识符或规范化姓名归并，不含作者本人；
+// 同名但标识符冲突的是不同的人，不按姓名归并，姓名对应多位作者后只按标识符归并
+func AnalyzeAuthor(author model.Author, papers []model.Paper) AuthorStats {
+	stats := AuthorStats{
+		Papers:    len(papers),
+		Years:     []YearCount{},
+		Venues:    []VenueCount{},
+		CoAuthors: []CoAuthor{},
+	}
+
+	citations := make([]int, 0, len(papers))
+	years := make(map[int]*YearCount)
+	venues := make(map[string]*VenueCount)
+	var coAuthors []CoAuthor
+	coAuthorIndex := make(map[string]int) // 标识符或规范化姓名 -> coAuthors 中的位置，姓名对应多位作者时为 -1
+	for _, p := range papers {
+		stats.Citations += p.Citations
+		citations = append(citations, p.Citations)
+
+		if p.Year != nil && *p.Year > 0 {
+			y, ok := years[*p.Year]
+			if !ok {
+				y = &YearCount{Year: *p.Year}
+				years[*p.Year] = y
+			}
+			y.Papers++
+			y.Citations += p.Citations
+		}
+
+		if !provider.IsPlaceholderVenue(p.Venue) {
+			key := strings.ToLower(strings.TrimSpace(p.Venue))
+			v, ok := venues[key]
+			if !ok {
+				v = &VenueCount{Venue: p.Venue, CCFClass: p.CCFClass}
+				if v.CCFClass == "" {
+					v.CCFClass = provider.GetCCFClass(p.Venue)
+				}
+				venues[key] = v
+			}
+			v.Count++
+		}
+
+		authors := provider.AuthorsOf(p)
+		self := provider.FindAuthor(authors, author)
+		for i, a := range authors {
+			if i == self || a.Name == "" {
+				continue
+			}
+			j := -1
+			for _, k := range coAuthorKeys(a) {
+				i, ok := coAuthorIndex[k]
+				if !ok || i < 0 {
+					continue
+				}
+				if _, conflict := provider.CompareAuthorIDs(coAuthors[i].Author, a); conflict {
+					continue
+				}
+				j = i
+				break
+			}
+			if j < 0 {
+				j = len(coAuthors)
+				coAuthors = append(coAuthors, CoAuthor{Author: model.Author{Name: a.Name}})
+			}
+			c := &coAuthors[j]
+			c.Author = provider.MergeAuthor(c.Author, model.Author{
+				ORCID: a.ORCID, OpenAlexID: a.OpenAlexID, S2ID: a.S2ID, DBLPID: a.DBLPID,
+			})
+			c.Count++
+			for _, k := range coAuthorKeys(c.Author) {
+				if prev, ok := coAuthorIndex[k]; ok && prev != j && strings.HasPrefix(k, "name:") {
+					coAuthorIndex[k] = -1
+					continue
+				}
+				coAuthorIndex[k] = j
+			}
+		}
+	}
+	stats.HIndex = hIndex(citations)
+
+	for _, y := range years {
+		stats.Years = append(stats.Years, *y)
+	}
+	sort.Slice(stats.Years, func(i, j int) bool { return stats.Years[i].Year < stats.Years[j].Year })
+
+	for _, v := range venues {
+		stats.Venues = append(stats.Venues, *v)
+	}
+	sort.Slice(stats.Venues, func(i, j int) bool {
+		if stats.Venues[i].Count != stats.Venues[j].Count {
+			return stats.Venues[i].Count > stats.Venues[j].Count
+		}
+		return stats.Venues[i].Venue < stats.Venues[j].Venue
+	})
+	if len(stats.Venues) > maxAuthorVenues {
+		stats.Venues = stats.Venues[:maxAuthorVenues]
+	}
+
+	// 只合作过一次的不算常见合作者
+	for _, c := range coAuthors {
+		if c.Count >= 2 {
+			stats.CoAuthors = append(stats.CoAuthors, c)
+		}
+	}
+	sort.Slice(stats.CoAuthors, func(i, j int) bool {
+		if stats.CoAuthors[i].Count != stats.CoAuthors[j].Count {
+			return stats.CoAuthors[i].Count > stats.CoAuthors[j].Count
+		}
+		return stats.CoAuthors[i].Author.Name < stats.CoAuthors[j].Author.Name
+	})
+	if len(stats.CoAuthors) > maxCoAuthors {
+		stats.CoAuthors = stats.CoAuthors[:maxCoAuthors]
+	}
+	return stats
+}
+
+// coAuthorKeys 返回合作者的归并键：各类标识符与规范化姓名
+func coAuthorKeys(a model.Author) []string {
+	var keys []string
+	for _, id := range [][2]string{
+		{"orcid:", a.ORCID},
+		{"openalex:", a.OpenAlexID},
+		{"s2:", a.S2ID},
+		{"dblp:", a.DBLPID},
+		{"name:", provider.AuthorNameKey(a.Name)},
+	} {
+		if id[1] != "" {
+			keys = append(keys, id[0]+id[1])
+		}
+	}
+	return keys
+}
+
+// hIndex 返回最大的 h，使得至少有 h 篇论文的引用量不少于 h
+func hIndex(citations []int) int {
+	sorted := append([]int(nil), citations...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	h := 0
+	for i, c := range sorted {
+		if c < i+1 {
+			break
+		}
+		h = i + 1
+	}
+	return h
+}
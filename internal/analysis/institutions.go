@@ -0,0 +1,84 @@
+package analysis
+
+import (
+	"sort"
+	"strings"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+)
+
+const (
+	// maxInstitutions 是每日摘要列出的机构数
+	maxInstitutions = 10
+	// maxNotablePapers 是每日摘要列出的重点机构论文数
+	maxNotablePapers = 5
+)
+
+// InstitutionCount 是机构在当天论文中出现的论文数
+type InstitutionCount struct {
+	Name    string `json:"name"`
+	ROR     string `json:"ror,omitempty"`
+	Country string `json:"country,omitempty"`
+	Count   int    `json:"count"`
+	Notable bool   `json:"notable"` // 是否属于重点机构
+}
+
+// isNotable 判断机构是否属于重点机构：列表项可以是 ROR ID，或以完整单词出现在机构名称中的名称片段（如 "Stanford"）
+func isNotable(inst model.Institution, notable []string) bool {
+	name := strings.ToLower(inst.Name)
+	for _, entry := range notable {
+		if inst.ROR != "" && provider.InstitutionKey(model.Institution{ROR: entry}) == provider.InstitutionKey(inst) {
+			return true
+		}
+		if entry != "" && containsPhrase(name, strings.ToLower(entry)) {
+			return true
+		}
+	}
+	return false
+}
+
+// analyzeInstitutions 统计各机构的论文数（按论文数降序），并挑出作者来自重点机构的论文
+func analyzeInstitutions(papers []model.Paper, notable []string) ([]InstitutionCount, []PaperWithOneLiner) {
+	counts := make(map[string]*InstitutionCount)
+	highlights := make([]PaperWithOneLiner, 0)
+	for _, p := range papers {
+		seen := make(map[string]bool)
+		fromNotable := false
+		for _, inst := range p.Institutions {
+			key := provider.InstitutionKey(inst)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			c, ok := counts[key]
+			if !ok {
+				c = &InstitutionCount{Name: inst.Name, ROR: inst.ROR, Country: inst.Country, Notable: isNotable(inst, notable)}
+				counts[key] = c
+			}
+			c.Count++
+			fromNotable = fromNotable || c.Notable
+		}
+		if fromNotable && len(highlights) < maxNotablePapers {
+			highlights = append(highlights, PaperWithOneLiner{Paper: p, OneLiner: oneLinerOf(p)})
+		}
+	}
+
+	list := make([]InstitutionCount, 0, len(counts))
+	for _, c := range counts {
+		list = append(list, *c)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		if list[i].Notable != list[j].Notable {
+			return list[i].Notable
+		}
+		return list[i].Name < list[j].Name
+	})
+	if len(list) > maxInstitutions {
+		list = list[:maxInstitutions]
+	}
+	return list, highlights
+}
@@ -0,0 +1,331 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"paper-scraper/internal/model"
+)
+
+const (
+	// maxTopics 是 TopTopics 返回的主题数
+	maxTopics = 10
+	// maxTopicExamples 是每个主题附带的示例论文数
+	maxTopicExamples = 3
+	// minTopicPapers 是主题至少出现的论文数
+	minTopicPapers = 2
+)
+
+// GenericWords 单独出现时不作为主题，但可以构成短语（如 "language model"、"reinforcement learning"）
+var GenericWords = map[string]bool{
+	"learning": true, "network": true, "model": true, "approach": true,
+	"method": true, "system": true, "analysis": true, "large": true,
+	"data": true, "language": true, "framework": true, "result": true,
+	"task": true, "problem": true, "efficient": true, "effective": true,
+	"study": true, "survey": true, "review": true, "performance": true,
+	"improving": true, "toward": true, "work": true, "paper": true,
+	"experiment": true, "benchmark": true, "training": true, "algorithm": true,
+}
+
+// phraseConnectors 可以出现在三词短语的中间（如 "mixture of experts"、"chain of thought"）
+var phraseConnectors = map[string]bool{"of": true}
+
+// 以 s 结尾但不是复数的词
+var singularS = map[string]bool{
+	"bias": true, "alias": true, "atlas": true, "canvas": true, "gas": true,
+	"lens": true, "news": true, "series": true, "species": true, "chaos": true,
+	"this": true, "thus": true, "has": true, "was": true, "its": true,
+}
+
+// Corpus 是背景语料的文档频率，用于计算主题的 IDF。
+// 背景语料通常取当天之前一段时间（如 30 天）内的论文，使每天都在出现的词（如 "language model"）得分较低
+type Corpus struct {
+	docs int
+	df   map[string]int
+}
+
+// NewCorpus 统计 papers 中每个候选主题出现的论文数
+func NewCorpus(papers []model.Paper) *Corpus {
+	c := &Corpus{df: make(map[string]int)}
+	for _, p := range papers {
+		c.docs++
+		for key := range paperTopics(p) {
+			c.df[key]++
+		}
+	}
+	return c
+}
+
+// Len 返回背景语料的论文数
+func (c *Corpus) Len() int {
+	if c == nil {
+		return 0
+	}
+	return c.docs
+}
+
+// idf 使用平滑的 IDF：ln((1+N)/(1+df)) + 1
+func (c *Corpus) idf(key string) float64 {
+	return math.Log(float64(1+c.docs)/float64(1+c.df[key])) + 1
+}
+
+type topicCandidate struct {
+	key      string
+	words    []string
+	display  string
+	trend    bool
+	count    int
+	score    float64
+	examples []string
+	surfaces map[string]int
+}
+
+// ExtractTopics 从论文的标题与摘要中提取关键短语（1~3 个词，复数还原为单数），
+// 命中 TrendKeywords 的论文同时计入对应的趋势主题。得分为出现的论文数乘以背景语料中的 IDF，
+// 多词短语略微加权；被更高分短语包含（或包含更高分短语）的候选不重复列出。
+// background 为空时以 papers 自身作为背景语料
+func ExtractTopics(papers []model.Paper, background *Corpus, limit int) []TopicCount {
+	if background.Len() == 0 {
+		background = NewCorpus(papers)
+	}
+
+	candidates := make(map[string]*topicCandidate)
+	for _, p := range papers {
+		for key, t := range paperTopics(p) {
+			c, ok := candidates[key]
+			if !ok {
+				c = &topicCandidate{key: key, words: strings.Fields(key), surfaces: make(map[string]int)}
+				candidates[key] = c
+			}
+			c.count++
+			c.surfaces[t.surface]++
+			if t.trend {
+				c.trend = true
+				c.display = t.surface
+			}
+			if len(c.examples) < maxTopicExamples && p.ID != "" {
+				c.examples = append(c.examples, p.ID)
+			}
+		}
+	}
+
+	ranked := make([]*topicCandidate, 0, len(candidates))
+	for _, c := range candidates {
+		if c.count < minTopicPapers {
+			continue
+		}
+		c.score = float64(c.count) * background.idf(c.key) * (1 + 0.5*float64(len(c.words)-1))
+		if !c.trend {
+			c.display = mostCommon(c.surfaces)
+		}
+		ranked = append(ranked, c)
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].score != ranked[j].score {
+			return ranked[i].score > ranked[j].score
+		}
+		return ranked[i].key < ranked[j].key
+	})
+
+	topics := make([]TopicCount, 0, limit)
+	var picked [][]string
+	for _, c := range ranked {
+		if len(topics) >= limit {
+			break
+		}
+		overlap := false
+		for _, words := range picked {
+			if containsWords(words, c.words) || containsWords(c.words, words) {
+				overlap = true
+				break
+			}
+		}
+		if overlap {
+			continue
+		}
+		picked = append(picked, c.words)
+		topics = append(topics, TopicCount{
+			Topic:    c.display,
+			Count:    c.count,
+			Score:    math.Round(c.score*100) / 100,
+			Examples: c.examples,
+		})
+	}
+	return topics
+}
+
+type paperTopic struct {
+	surface string
+	trend   bool
+}
+
+// paperTopics 返回论文中出现的候选主题，键为还原后的小写短语
+func paperTopics(p model.Paper) map[string]paperTopic {
+	topics := make(map[string]paperTopic)
+	for _, text := range []string{p.Title, p.Abstract} {
+		for _, segment := range segments(text) {
+			addPhrases(topics, segment)
+		}
+	}
+	// 趋势关键字使用统一的显示名称，与提取出的同名短语合并
+	combined := strings.ToLower(p.Title + " " + p.Abstract)
+	for kw, display := range TrendKeywords {
+		if containsPhrase(combined, strings.ToLower(kw)) {
+			topics[lemmaKey(strings.Fields(strings.ToLower(display)))] = paperTopic{surface: display, trend: true}
+		}
+	}
+	return topics
+}
+
+// segments 按标点把文本切分为若干段，短语不跨越标点；词保留连字符（如 zero-shot）
+func segments(text string) [][]string {
+	var segs [][]string
+	var cur []string
+	var word strings.Builder
+	flushWord := func() {
+		w := strings.Trim(word.String(), "-")
+		word.Reset()
+		if w == "" {
+			return
+		}
+		// 纯数字不构成主题，同时作为短语边界
+		if strings.IndexFunc(w, func(r rune) bool { return !unicode.IsDigit(r) && r != '-' }) < 0 {
+			flushSeg(&segs, &cur)
+			return
+		}
+		cur = append(cur, strings.ToLower(w))
+	}
+	for _, r := range text {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-':
+			word.WriteRune(r)
+		case unicode.IsSpace(r):
+			flushWord()
+		default:
+			flushWord()
+			flushSeg(&segs, &cur)
+		}
+	}
+	flushWord()
+	flushSeg(&segs, &cur)
+	return segs
+}
+
+func flushSeg(segs *[][]string, cur *[]string) {
+	if len(*cur) > 0 {
+		*segs = append(*segs, *cur)
+		*cur = nil
+	}
+}
+
+// addPhrases 提取段内的 1~3 词短语：首尾不能是停用词，三词短语的中间词可以是连接词 of
+func addPhrases(topics map[string]paperTopic, words []string) {
+	isStop := func(w string) bool { return StopWords[w] || len(w) < 2 }
+	for i := range words {
+		if isStop(words[i]) {
+			continue
+		}
+		for n := 1; n <= 3 && i+n <= len(words); n++ {
+			phrase := words[i : i+n]
+			last := phrase[n-1]
+			if isStop(last) {
+				if n == 2 && phraseConnectors[last] {
+					continue
+				}
+				break
+			}
+			key := lemmaKey(phrase)
+			if n == 1 && GenericWords[key] {
+				continue
+			}
+			if _, ok := topics[key]; !ok {
+				topics[key] = paperTopic{surface: strings.Join(phrase, " ")}
+			}
+		}
+	}
+}
+
+// lemmaKey 把短语中的每个词还原为单数后拼接；连字符视为空格，使 "chain-of-thought" 与 "chain of thought" 合并
+func lemmaKey(words []string) string {
+	var lemmas []string
+	for _, w := range words {
+		for _, part := range strings.Split(w, "-") {
+			if part != "" {
+				lemmas = append(lemmas, lemma(part))
+			}
+		}
+	}
+	return strings.Join(lemmas, " ")
+}
+
+// lemma 把英文复数名词还原为单数（models -> model，studies -> study，approaches -> approach）
+func lemma(w string) string {
+	switch {
+	case len(w) <= 3 || singularS[w]:
+		return w
+	case strings.HasSuffix(w, "ies") && len(w) > 4:
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "ches"),
+		strings.HasSuffix(w, "shes"), strings.HasSuffix(w, "xes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"),
+		strings.HasSuffix(w, "is"), strings.HasSuffix(w, "ics"):
+		return w
+	case strings.HasSuffix(w, "s"):
+		return w[:len(w)-1]
+	}
+	return w
+}
+
+// containsPhrase 判断 text 中是否以完整的词出现 phrase（允许复数 s 结尾），
+// 避免 "rag" 命中 "average"、"storage" 这类子串
+func containsPhrase(text, phrase string) bool {
+	for from := 0; ; {
+		i := strings.Index(text[from:], phrase)
+		if i < 0 {
+			return false
+		}
+		i += from
+		end := i + len(phrase)
+		if end < len(text) && text[end] == 's' {
+			end++
+		}
+		if (i == 0 || !isWordByte(text[i-1])) && (end == len(text) || !isWordByte(text[end])) {
+			return true
+		}
+		from = i + 1
+	}
+}
+
+func isWordByte(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b >= 0x80
+}
+
+// containsWords 判断 words 中是否连续包含 sub
+func containsWords(words, sub []string) bool {
+	for i := 0; i+len(sub) <= len(words); i++ {
+		match := true
+		for j := range sub {
+			if words[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func mostCommon(counts map[string]int) string {
+	best, bestN := "", 0
+	for s, n := range counts {
+		if n > bestN || n == bestN && s < best {
+			best, bestN = s, n
+		}
+	}
+	return best
+}
@@ -0,0 +1,329 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+)
+
+const (
+	// TrendBaselineDays 是突发检测的基线期天数
+	TrendBaselineDays = 30
+	// minBaselineDays 是基线期内至少有数据的天数，不足时不做检测
+	minBaselineDays = 7
+	// burstZ 是判定突发（上升）或下降的 z 分数阈值
+	burstZ = 2.0
+	// minFallingExpected 是判定下降时，按基线占比预期在近期窗口出现的最少论文数
+	minFallingExpected = 3.0
+	// maxTrendTopics 是上升、下降主题各自返回的数量
+	maxTrendTopics = 10
+)
+
+// TopicHistory 按发布日期统计每天的论文数与各主题（见 ExtractTopics）出现的论文数
+type TopicHistory struct {
+	days    map[string]*dayTopics
+	display map[string]string
+	corpus  *Corpus
+}
+
+type dayTopics struct {
+	total  int
+	counts map[string]int
+}
+
+// TrendPoint 是主题时间序列中的一天
+type TrendPoint struct {
+	Date   string  `json:"date"`
+	Count  int     `json:"count"` // 当天出现该主题的论文数
+	Total  int     `json:"total"` // 当天的论文总数
+	Share  float64 `json:"share"`
+	ZScore float64 `json:"z_score"` // 相对此前 30 天基线的 z 分数
+	Burst  bool    `json:"burst"`
+}
+
+// TopicTrend 是主题在近期窗口内相对基线期的变化
+type TopicTrend struct {
+	Topic    string  `json:"topic"`
+	Count    int     `json:"count"`    // 近期窗口内出现的论文数
+	Share    float64 `json:"share"`    // 近期窗口内的占比
+	Baseline float64 `json:"baseline"` // 基线期每日占比的均值
+	ZScore   float64 `json:"z_score"`
+	Growth   float64 `json:"growth"` // 相对基线的增长率，1 表示翻倍，-0.5 表示减半
+}
+
+// TrendReport 汇总近期窗口 [From, To] 相对其前 30 天基线的上升与下降主题
+type TrendReport struct {
+	From         string       `json:"from"`
+	To           string       `json:"to"`
+	BaselineFrom string       `json:"baseline_from"`
+	BaselineTo   string       `json:"baseline_to"`
+	BaselineDays int          `json:"baseline_days"` // 基线期内有数据的天数
+	Rising       []TopicTrend `json:"rising"`
+	Falling      []TopicTrend `json:"falling"`
+}
+
+// NewTopicHistory 统计 papers 的每日主题分布，没有发布日期的论文不计入
+func NewTopicHistory(papers []model.Paper) *TopicHistory {
+	b := NewTopicHistoryBuilder()
+	for _, p := range papers {
+		b.Add(p)
+	}
+	return b.Build()
+}
+
+// TopicHistoryBuilder 逐篇累计每日主题分布，不保留论文本身，
+// 适合直接在本地论文库的遍历中统计大量历史论文
+type TopicHistoryBuilder struct {
+	h *TopicHistory
+}
+
+func NewTopicHistoryBuilder() *TopicHistoryBuilder {
+	return &TopicHistoryBuilder{h: &TopicHistory{
+		days:    make(map[string]*dayTopics),
+		display: make(map[string]string),
+		corpus:  &Corpus{df: make(map[string]int)},
+	}}
+}
+
+// Add 计入一篇论文，没有发布日期的论文不计入
+func (b *TopicHistoryBuilder) Add(p model.Paper) {
+	h := b.h
+	t := provider.ParseDate(p.PublishedAt)
+	if t.IsZero() {
+		return
+	}
+	date := t.Format("2006-01-02")
+	day, ok := h.days[date]
+	if !ok {
+		day = &dayTopics{counts: make(map[string]int)}
+		h.days[date] = day
+	}
+	day.total++
+	h.corpus.docs++
+	for key, topic := range paperTopics(p) {
+		day.counts[key]++
+		h.corpus.df[key]++
+		if _, ok := h.display[key]; !ok || topic.trend {
+			h.display[key] = topic.surface
+		}
+	}
+}
+
+// Build 返回统计结果。只出现在一篇论文中的主题不会成为趋势，此时丢弃以节省内存（背景语料仍包含全部主题）。
+// Build 之后不应再调用 Add
+func (b *TopicHistoryBuilder) Build() *TopicHistory {
+	h := b.h
+	for _, day := range h.days {
+		for key := range day.counts {
+			if h.corpus.df[key] < minTopicPapers {
+				delete(day.counts, key)
+			}
+		}
+	}
+	for key := range h.display {
+		if h.corpus.df[key] < minTopicPapers {
+			delete(h.display, key)
+		}
+	}
+	return h
+}
+
+// Corpus 返回全部论文构成的背景语料
+func (h *TopicHistory) Corpus() *Corpus {
+	if h == nil {
+		return nil
+	}
+	return h.corpus
+}
+
+// TopicKey 把主题名称规范化为统计使用的键（小写、复数还原）
+func TopicKey(topic string) string {
+	return lemmaKey(strings.Fields(strings.ToLower(topic)))
+}
+
+// baseline 返回主题在 [from, to] 内每日占比的均值与标准差，以及有数据的天数与论文总数
+func (h *TopicHistory) baseline(key string, from, to time.Time) (mean, std float64, days, total int) {
+	var shares []float64
+	for d := from; !d.After(to); d = d.AddDate(0, 0, 1) {
+		day, ok := h.days[d.Format("2006-01-02")]
+		if !ok || day.total == 0 {
+			continue
+		}
+		shares = append(shares, float64(day.counts[key])/float64(day.total))
+		total += day.total
+	}
+	if len(shares) == 0 {
+		return 0, 0, 0, 0
+	}
+	for _, s := range shares {
+		mean += s
+	}
+	mean /= float64(len(shares))
+	for _, s := range shares {
+		std += (s - mean) * (s - mean)
+	}
+	std = math.Sqrt(std / float64(len(shares)))
+	return mean, std, len(shares), total
+}
+
+// zScore 计算近期占比（count/total，覆盖 days 天）相对基线的 z 分数与增长率。
+// 标准差取基线日间波动（按天数缩放）与二项分布抽样误差中的较大者，避免基线几乎不变的主题因很小的波动被判为突发
+func zScore(count, total, days int, mean, std float64, baselineTotal int) (z, growth float64) {
+	share := float64(count) / float64(total)
+	// 基线中从未出现的主题按半篇论文估计其占比
+	floor := mean
+	if floor == 0 {
+		floor = 0.5 / float64(max(baselineTotal, 1))
+	}
+	sigma := math.Max(std/math.Sqrt(float64(max(days, 1))), math.Sqrt(floor*(1-floor)/float64(total)))
+	return (share - mean) / sigma, (share - mean) / floor
+}
+
+// DetectTrends 比较近期窗口 [from, to]（取自 recent）与其前 30 天基线期（取自 history）中各主题的占比，
+// z 分数超过阈值的为上升（突发）主题，低于负阈值的为下降主题，各自按 z 分数排序。
+// history 与 recent 可以是同一个 TopicHistory
+func DetectTrends(history, recent *TopicHistory, from, to string) TrendReport {
+	fromT, _ := time.Parse("2006-01-02", from)
+	toT, _ := time.Parse("2006-01-02", to)
+	baseFrom, baseTo := fromT.AddDate(0, 0, -TrendBaselineDays), fromT.AddDate(0, 0, -1)
+	report := TrendReport{
+		From:         from,
+		To:           to,
+		BaselineFrom: baseFrom.Format("2006-01-02"),
+		BaselineTo:   baseTo.Format("2006-01-02"),
+		Rising:       []TopicTrend{},
+		Falling:      []TopicTrend{},
+	}
+	if history == nil || recent == nil {
+		return report
+	}
+
+	// 近期窗口的主题计数
+	counts := make(map[string]int)
+	total, days := 0, 0
+	for d := fromT; !d.After(toT); d = d.AddDate(0, 0, 1) {
+		day, ok := recent.days[d.Format("2006-01-02")]
+		if !ok || day.total == 0 {
+			continue
+		}
+		days++
+		total += day.total
+		for key, n := range day.counts {
+			counts[key] += n
+		}
+	}
+	_, _, report.BaselineDays, _ = history.baseline("", baseFrom, baseTo)
+	if total == 0 || report.BaselineDays < minBaselineDays {
+		return report
+	}
+
+	// 候选主题：近期出现的主题（上升）与基线期出现的主题（下降）
+	candidates := make(map[string]bool)
+	for key, n := range counts {
+		if n >= minTopicPapers {
+			candidates[key] = true
+		}
+	}
+	for d := baseFrom; !d.After(baseTo); d = d.AddDate(0, 0, 1) {
+		if day, ok := history.days[d.Format("2006-01-02")]; ok {
+			for key := range day.counts {
+				candidates[key] = true
+			}
+		}
+	}
+
+	var rising, falling []TopicTrend
+	for key := range candidates {
+		mean, std, _, baseTotal := history.baseline(key, baseFrom, baseTo)
+		z, growth := zScore(counts[key], total, days, mean, std, baseTotal)
+		trend := TopicTrend{
+			Topic:    key,
+			Count:    counts[key],
+			Share:    roundTo(float64(counts[key])/float64(total), 4),
+			Baseline: roundTo(mean, 4),
+			ZScore:   roundTo(z, 2),
+			Growth:   roundTo(growth, 2),
+		}
+		if name, ok := recent.display[key]; ok {
+			trend.Topic = name
+		} else if name, ok := history.display[key]; ok {
+			trend.Topic = name
+		}
+		switch {
+		case z >= burstZ && counts[key] >= minTopicPapers:
+			rising = append(rising, trend)
+		case z <= -burstZ && mean*float64(total) >= minFallingExpected:
+			falling = append(falling, trend)
+		}
+	}
+	report.Rising = topTrends(rising, func(t TopicTrend) float64 { return t.ZScore })
+	report.Falling = topTrends(falling, func(t TopicTrend) float64 { return -t.ZScore })
+	return report
+}
+
+// Series 返回主题在 [from, to] 内的每日时间序列，每天的 z 分数相对此前 30 天的基线计算
+func (h *TopicHistory) Series(topic, from, to string) []TrendPoint {
+	key := TopicKey(topic)
+	fromT, _ := time.Parse("2006-01-02", from)
+	toT, _ := time.Parse("2006-01-02", to)
+	points := make([]TrendPoint, 0)
+	for d := fromT; !d.After(toT); d = d.AddDate(0, 0, 1) {
+		point := TrendPoint{Date: d.Format("2006-01-02")}
+		if day, ok := h.days[point.Date]; ok && day.total > 0 {
+			point.Count, point.Total = day.counts[key], day.total
+			point.Share = roundTo(float64(point.Count)/float64(point.Total), 4)
+			mean, std, days, baseTotal := h.baseline(key, d.AddDate(0, 0, -TrendBaselineDays), d.AddDate(0, 0, -1))
+			if days >= minBaselineDays {
+				z, _ := zScore(point.Count, point.Total, 1, mean, std, baseTotal)
+				point.ZScore = roundTo(z, 2)
+				point.Burst = z >= burstZ && point.Count >= minTopicPapers
+			}
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// topTrends 按 score 降序排序（相同时长短语优先），去掉与更靠前主题互相包含的短语，最多保留 maxTrendTopics 个
+func topTrends(trends []TopicTrend, score func(TopicTrend) float64) []TopicTrend {
+	sort.Slice(trends, func(i, j int) bool {
+		if score(trends[i]) != score(trends[j]) {
+			return score(trends[i]) > score(trends[j])
+		}
+		// 同时出现的词得分相同，优先保留更长的短语
+		if li, lj := len(strings.Fields(trends[i].Topic)), len(strings.Fields(trends[j].Topic)); li != lj {
+			return li > lj
+		}
+		return trends[i].Topic < trends[j].Topic
+	})
+	result := make([]TopicTrend, 0, maxTrendTopics)
+	var picked [][]string
+	for _, t := range trends {
+		if len(result) >= maxTrendTopics {
+			break
+		}
+		words := strings.Fields(TopicKey(t.Topic))
+		overlap := false
+		for _, w := range picked {
+			if containsWords(w, words) || containsWords(words, w) {
+				overlap = true
+				break
+			}
+		}
+		if overlap {
+			continue
+		}
+		picked = append(picked, words)
+		result = append(result, t)
+	}
+	return result
+}
+
+func roundTo(v float64, digits int) float64 {
+	p := math.Pow(10, float64(digits))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"testing"
+	"time"
+
+	"paper-scraper/internal/model"
+)
+
+// trendPapers 生成 [from, from+days) 内每天每个标题各 perDay 篇论文
+func trendPapers(from string, days, perDay int, titles ...string) []model.Paper {
+	start, _ := time.Parse("2006-01-02", from)
+	var papers []model.Paper
+	for d := 0; d < days; d++ {
+		date := start.AddDate(0, 0, d).Format("2006-01-02")
+		for _, title := range titles {
+			for i := 0; i < perDay; i++ {
+				papers = append(papers, model.Paper{Title: title, PublishedAt: date})
+			}
+		}
+	}
+	return papers
+}
+
+func topicNames(trends []TopicTrend) map[string]bool {
+	names := make(map[string]bool, len(trends))
+	for _, t := range trends {
+		names[t.Topic] = true
+	}
+	return names
+}
+
+func TestDetectTrends(t *testing.T) {
+	const (
+		graphs  = "Graph neural networks for molecules"
+		convnet = "Convolutional networks for images"
+		video   = "Diffusion models for video"
+	)
+	// 近期窗口为 2024-05-31 至 2024-06-06，基线期为此前 30 天
+	tests := []struct {
+		name         string
+		papers       []model.Paper
+		baselineDays int
+		rising       []string
+		falling      []string
+		stable       []string // 既不上升也不下降
+	}{
+		{
+			name: "rising and falling",
+			papers: append(trendPapers("2024-05-01", 30, 2, graphs, convnet),
+				trendPapers("2024-05-31", 7, 2, graphs, video)...),
+			baselineDays: 30,
+			rising:       []string{"Diffusion Models", "video"},
+			falling:      []string{"convolutional networks", "images"},
+			stable:       []string{"graph neural networks", "molecules"},
+		},
+		{
+			name: "short baseline",
+			papers: append(trendPapers("2024-05-26", 5, 2, graphs, convnet),
+				trendPapers("2024-05-31", 7, 2, graphs, video)...),
+			baselineDays: 5,
+		},
+		{
+			name:         "no recent papers",
+			papers:       trendPapers("2024-05-01", 30, 2, graphs, convnet),
+			baselineDays: 30,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewTopicHistory(tt.papers)
+			report := DetectTrends(h, h, "2024-05-31", "2024-06-06")
+			if report.BaselineFrom != "2024-05-01" || report.BaselineTo != "2024-05-30" {
+				t.Errorf("baseline = %s..%s, want 2024-05-01..2024-05-30", report.BaselineFrom, report.BaselineTo)
+			}
+			if report.BaselineDays != tt.baselineDays {
+				t.Errorf("BaselineDays = %d, want %d", report.BaselineDays, tt.baselineDays)
+			}
+			rising, falling := topicNames(report.Rising), topicNames(report.Falling)
+			if len(rising) != len(tt.rising) || len(falling) != len(tt.falling) {
+				t.Errorf("rising = %v, falling = %v, want %v and %v", report.Rising, report.Falling, tt.rising, tt.falling)
+			}
+			for _, topic := range tt.rising {
+				if !rising[topic] {
+					t.Errorf("%q not rising: %v", topic, report.Rising)
+				}
+			}
+			for _, topic := range tt.falling {
+				if !falling[topic] {
+					t.Errorf("%q not falling: %v", topic, report.Falling)
+				}
+			}
+			for _, topic := range tt.stable {
+				if rising[topic] || falling[topic] {
+					t.Errorf("stable topic %q reported as a trend", topic)
+				}
+			}
+		})
+	}
+}
+
+func TestDetectTrendsNilHistory(t *testing.T) {
+	report := DetectTrends(nil, nil, "2024-05-31", "2024-06-06")
+	if report.Rising == nil || report.Falling == nil || len(report.Rising)+len(report.Falling) != 0 {
+		t.Errorf("report = %+v, want empty non-nil lists", report)
+	}
+}
+
+func TestSeries(t *testing.T) {
+	h := NewTopicHistory(append(
+		trendPapers("2024-05-01", 30, 2, "Graph neural networks for molecules"),
+		trendPapers("2024-05-31", 2, 2, "Diffusion models for video")...,
+	))
+	points := h.Series("diffusion model", "2024-05-30", "2024-06-02")
+	want := []struct {
+		date         string
+		count, total int
+		burst        bool
+	}{
+		{"2024-05-30", 0, 2, false},
+		{"2024-05-31", 2, 2, true},
+		{"2024-06-01", 2, 2, true},
+		{"2024-06-02", 0, 0, false},
+	}
+	if len(points) != len(want) {
+		t.Fatalf("got %d points, want %d", len(points), len(want))
+	}
+	for i, w := range want {
+		p := points[i]
+		if p.Date != w.date || p.Count != w.count || p.Total != w.total || p.Burst != w.burst {
+			t.Errorf("point %d = %+v, want %+v", i, p, w)
+		}
+	}
+}
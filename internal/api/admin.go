@@ -0,0 +1,73 @@
+package api
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+	"strings"
+
+	"paper-scraper/internal/scheduler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// jobScheduler 为后台任务调度器；为 nil 时管理接口返回 503
+var jobScheduler *scheduler.Scheduler
+
+// adminToken 为 /admin 接口要求的 Authorization: Bearer <token>；为空时拒绝所有请求
+var adminToken string
+
+// SetScheduler 设置管理接口使用的任务调度器
+func SetScheduler(s *scheduler.Scheduler) {
+	jobScheduler = s
+}
+
+// SetAdminToken 设置管理接口的访问令牌，空串表示禁用管理接口
+func SetAdminToken(token string) {
+	adminToken = token
+}
+
+// AdminAuth 校验管理接口的访问令牌。未配置令牌时返回 403：
+// 管理接口可触发全量摄取等耗时任务，不能在未鉴权的情况下对外开放
+func AdminAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if adminToken == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "admin api is disabled, set SCHOLARX_ADMIN_TOKEN to enable it"})
+			return
+		}
+		token := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ListJobs 处理 GET /admin/jobs，返回各后台任务的时间表、运行状态与最近一次运行结果
+func ListJobs(c *gin.Context) {
+	if jobScheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "scheduler is not running"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"jobs": jobScheduler.Status()})
+}
+
+// RunJob 处理 POST /admin/jobs/:name/run，立即在后台运行一次任务
+func RunJob(c *gin.Context) {
+	if jobScheduler == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "scheduler is not running"})
+		return
+	}
+	name := c.Param("name")
+	switch err := jobScheduler.Run(name); {
+	case errors.Is(err, scheduler.ErrUnknownJob):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, scheduler.ErrRunning):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusAccepted, gin.H{"job": name, "status": "started"})
+	}
+}
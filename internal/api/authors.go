@@ -0,0 +1,390 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"paper-scraper/internal/analysis"
+	"paper-scraper/internal/cache"
+	"paper-scraper/internal/merge"
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// authorCacheTTL 是作者档案的缓存有效期
+	authorCacheTTL = 6 * time.Hour
+	// defaultAuthorPapers 与 maxAuthorPapers 是每个数据源读取的作者论文数的默认值与上限
+	defaultAuthorPapers = 200
+	maxAuthorPapers     = 1000
+	// maxAuthorCandidates 是按姓名检索时每个数据源返回的候选作者数
+	maxAuthorCandidates = 10
+	// maxLinkDOIs 是按 DOI 关联 OpenAlex 作者时查询的论文数
+	maxLinkDOIs = 50
+)
+
+// authorProfile 是 /authors 与 /authors/:id 的响应体
+type authorProfile struct {
+	Author   model.Author          `json:"author"`   // 跨数据源合并的作者（标识符与所属机构）
+	Profiles []provider.AuthorInfo `json:"profiles"` // 各数据源自身的作者档案与统计
+
+	// 总体指标取各数据源报告的最大值，覆盖作者的全部论文
+	WorksCount int `json:"works_count"`
+	Citations  int `json:"citations"`
+	HIndex     int `json:"h_index"`
+	// Fetched 只统计已读取的论文（每个数据源最新的 limit 篇），多产作者的较早论文不在其中
+	Fetched analysis.AuthorStats `json:"fetched"`
+
+	Papers      []model.Paper         `json:"papers"`
+	Sources     []model.SourceStatus  `json:"sources"`
+	Candidates  []provider.AuthorInfo `json:"candidates,omitempty"` // 按姓名检索时的候选作者
+	GeneratedAt time.Time             `json:"generated_at"`
+}
+
+// authorRef 指向某个数据源中的作者：source 为 openalex（id 为 OpenAlex ID 或 ORCID）或 s2
+type authorRef struct {
+	source string
+	id     string
+}
+
+// parseAuthorRef 识别作者 ID：可带 openalex:、s2:、orcid: 前缀；
+// 否则 A 开头的数字为 OpenAlex ID，ORCID 格式按 ORCID 在 OpenAlex 中查找，纯数字为 S2 authorId
+func parseAuthorRef(raw string) (authorRef, bool) {
+	raw = strings.TrimSpace(raw)
+	if prefix, value, ok := strings.Cut(raw, ":"); ok {
+		switch strings.ToLower(prefix) {
+		case "openalex":
+			raw = value
+		case "orcid":
+			if id := provider.NormalizeORCID(value); provider.IsORCID(id) {
+				return authorRef{source: "openalex", id: id}, true
+			}
+			return authorRef{}, false
+		case "s2":
+			if _, err := strconv.Atoi(value); err == nil {
+				return authorRef{source: "s2", id: value}, true
+			}
+			return authorRef{}, false
+		default:
+			return authorRef{}, false
+		}
+	}
+	switch id := provider.NormalizeOpenAlexID(raw); {
+	case provider.IsOpenAlexAuthorID(id), provider.IsORCID(id):
+		return authorRef{source: "openalex", id: id}, true
+	}
+	if _, err := strconv.Atoi(raw); err == nil && raw != "" {
+		return authorRef{source: "s2", id: raw}, true
+	}
+	return authorRef{}, false
+}
+
+// authorLimit 读取 limit 参数（每个数据源读取的论文数）
+func authorLimit(c *gin.Context) int {
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		return defaultAuthorPapers
+	}
+	return min(limit, maxAuthorPapers)
+}
+
+// GetAuthor 处理 /authors/:id?limit=，id 为 OpenAlex 作者 ID、ORCID 或 S2 authorId（见 parseAuthorRef）。
+// 从对应数据源读取作者档案与论文，再通过 ORCID 或共同论文关联另一数据源中的同一作者，
+// 总论文数、总引用量与 h 指数取数据源报告的统计；合并两边读取到的论文后统计逐年产出、
+// 主要发表渠道（含 CCF 分级）与常见合作者
+func GetAuthor(c *gin.Context) {
+	ref, ok := parseAuthorRef(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid author id, expected an OpenAlex ID, ORCID or S2 author ID"})
+		return
+	}
+	limit := authorLimit(c)
+	key := cache.Key("author", ref.source, ref.id, strconv.Itoa(limit))
+	serveAuthorProfile(c, key, func(ctx context.Context) (*authorProfile, error) {
+		return buildAuthorProfile(ctx, ref, limit)
+	})
+}
+
+// SearchAuthors 处理 /authors?name=&limit=：在 OpenAlex 与 S2 中按姓名检索作者，
+// 返回最匹配的作者（规范化姓名一致者中论文最多的，否则为第一个结果）的档案，并附上全部候选作者
+func SearchAuthors(c *gin.Context) {
+	name := strings.TrimSpace(c.Query("name"))
+	if name == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name is required"})
+		return
+	}
+	limit := authorLimit(c)
+	key := cache.Key("author", "name", provider.AuthorNameKey(name), strconv.Itoa(limit))
+	serveAuthorProfile(c, key, func(ctx context.Context) (*authorProfile, error) {
+		candidates, statuses, err := searchAuthorCandidates(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		best := pickAuthor(name, candidates)
+		ref := authorRef{source: best.Source, id: best.Author.OpenAlexID}
+		if best.Source == "s2" {
+			ref.id = best.Author.S2ID
+		}
+		profile, err := buildAuthorProfile(ctx, ref, limit)
+		if err != nil {
+			return nil, err
+		}
+		profile.Candidates = candidates
+		profile.Sources = append(statuses, profile.Sources...)
+		return profile, nil
+	})
+}
+
+// serveAuthorProfile 返回缓存的作者档案；未命中或过期时调用 build 重新生成，
+// 生成失败时若有过期缓存则返回旧数据
+func serveAuthorProfile(c *gin.Context, key string, build func(ctx context.Context) (*authorProfile, error)) {
+	var cached authorProfile
+	fresh, ok := getCached(key, &cached)
+	if ok && fresh {
+		c.Header("X-Cache-Status", "author="+string(cache.StatusHit))
+		c.JSON(http.StatusOK, cached)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestDeadline)
+	defer cancel()
+	profile, err := build(ctx)
+	if err != nil {
+		if ok && !errors.Is(err, provider.ErrNotFound) {
+			fmt.Printf("author error, serving stale cache: %v\n", err)
+			c.Header("X-Cache-Status", "author="+string(cache.StatusStale))
+			c.JSON(http.StatusOK, cached)
+			return
+		}
+		if errors.Is(err, provider.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "author not found"})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	if setCached(key, profile, authorCacheTTL) {
+		c.Header("X-Cache-Status", "author="+string(cache.StatusMiss))
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// searchAuthorCandidates 并发在 OpenAlex 与 S2 中按姓名检索作者，OpenAlex 的候选在前。
+// 检索最多占用一半的请求时限，余下的时间用于读取作者档案。
+// 两个数据源都失败时返回错误，都没有结果时返回 ErrNotFound
+func searchAuthorCandidates(ctx context.Context, name string) ([]provider.AuthorInfo, []model.SourceStatus, error) {
+	ctx, cancel := context.WithTimeout(ctx, requestDeadline/2)
+	defer cancel()
+	searches := []struct {
+		source string
+		fn     func(context.Context, string, int) ([]provider.AuthorInfo, error)
+	}{
+		{"openalex", provider.SearchOpenAlexAuthors},
+		{"s2", provider.SearchS2Authors},
+	}
+	results := make([][]provider.AuthorInfo, len(searches))
+	statuses := make([]model.SourceStatus, len(searches))
+	var wg sync.WaitGroup
+	for i, s := range searches {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			start := time.Now()
+			authors, err := s.fn(ctx, name, maxAuthorCandidates)
+			results[i] = authors
+			statuses[i] = sourceStatus(s.source+":authors", start, len(authors), err)
+		}(i)
+	}
+	wg.Wait()
+
+	var candidates []provider.AuthorInfo
+	var lastErr error
+	for i, authors := range results {
+		candidates = append(candidates, authors...)
+		if statuses[i].Status != model.SourceStatusOK {
+			lastErr = errors.New(statuses[i].Error)
+		}
+	}
+	if len(candidates) == 0 {
+		if lastErr != nil {
+			return nil, statuses, lastErr
+		}
+		return nil, statuses, provider.ErrNotFound
+	}
+	return candidates, statuses, nil
+}
+
+// pickAuthor 在候选中选出规范化姓名与 name 一致且论文最多的作者，没有时取第一个候选
+func pickAuthor(name string, candidates []provider.AuthorInfo) provider.AuthorInfo {
+	key := provider.AuthorNameKey(name)
+	best := -1
+	for i, a := range candidates {
+		if provider.AuthorNameKey(a.Author.Name) != key {
+			continue
+		}
+		if best < 0 || a.WorksCount > candidates[best].WorksCount {
+			best = i
+		}
+	}
+	if best < 0 {
+		best = 0
+	}
+	return candidates[best]
+}
+
+// buildAuthorProfile 读取主数据源的作者与论文，关联另一数据源中的同一作者后合并统计。
+// 主数据源失败时返回错误；关联的数据源失败只记录在 sources 中
+func buildAuthorProfile(ctx context.Context, ref authorRef, limit int) (*authorProfile, error) {
+	profile := &authorProfile{GeneratedAt: time.Now()}
+	info, papers, status, err := fetchAuthorSource(ctx, ref, limit)
+	profile.Sources = append(profile.Sources, status)
+	if err != nil {
+		return nil, err
+	}
+	profile.Author = info.Author
+	profile.Profiles = []provider.AuthorInfo{info}
+
+	// 关联另一数据源：OpenAlex 作者通过 S2 补全论文后按作者匹配取得 S2 authorId；
+	// S2 作者优先按 ORCID，否则按共同论文（DOI）在 OpenAlex 中匹配
+	var linked authorRef
+	start := time.Now()
+	switch ref.source {
+	case "openalex":
+		if _, err := provider.EnrichS2(ctx, papers); err != nil {
+			fmt.Println("author s2 link error:", err)
+			profile.Sources = append(profile.Sources, sourceStatus("s2", start, 0, err))
+		}
+		if id := voteAuthorID(info.Author, papers, func(a model.Author) string { return a.S2ID }); id != "" {
+			linked = authorRef{source: "s2", id: id}
+		}
+	case "s2":
+		id, err := info.Author.ORCID, error(nil)
+		if id == "" {
+			id, err = linkOpenAlexAuthor(ctx, info.Author, papers)
+		}
+		if err != nil {
+			fmt.Println("author openalex link error:", err)
+			profile.Sources = append(profile.Sources, sourceStatus("openalex", start, 0, err))
+		}
+		if id != "" {
+			linked = authorRef{source: "openalex", id: id}
+		}
+	}
+	if linked.id != "" {
+		other, otherPapers, status, err := fetchAuthorSource(ctx, linked, limit)
+		profile.Sources = append(profile.Sources, status)
+		if err == nil {
+			profile.Author = provider.MergeAuthor(profile.Author, other.Author)
+			profile.Profiles = append(profile.Profiles, other)
+			papers = append(papers, otherPapers...)
+		}
+	}
+
+	papers = merge.Papers(papers)
+	sort.SliceStable(papers, func(i, j int) bool { return papers[i].PublishedAt > papers[j].PublishedAt })
+	profile.Fetched = analysis.AnalyzeAuthor(profile.Author, papers)
+	profile.WorksCount, profile.Citations, profile.HIndex = profile.Fetched.Papers, profile.Fetched.Citations, profile.Fetched.HIndex
+	for _, info := range profile.Profiles {
+		profile.WorksCount = max(profile.WorksCount, info.WorksCount)
+		profile.Citations = max(profile.Citations, info.Citations)
+		profile.HIndex = max(profile.HIndex, info.HIndex)
+	}
+	if len(papers) > limit {
+		papers = papers[:limit]
+	}
+	profile.Papers = papers
+	return profile, nil
+}
+
+// fetchAuthorSource 读取数据源中的作者档案与论文（最多 limit 篇）
+func fetchAuthorSource(ctx context.Context, ref authorRef, limit int) (provider.AuthorInfo, []model.Paper, model.SourceStatus, error) {
+	start := time.Now()
+	var info provider.AuthorInfo
+	var papers []model.Paper
+	var err error
+	switch ref.source {
+	case "openalex":
+		if info, err = provider.FetchOpenAlexAuthor(ctx, ref.id); err == nil {
+			papers, err = provider.FetchOpenAlexAuthorWorks(ctx, info.Author.OpenAlexID, limit)
+		}
+	case "s2":
+		if info, err = provider.FetchS2Author(ctx, ref.id); err == nil {
+			papers, err = provider.FetchS2AuthorPapers(ctx, ref.id, limit)
+		}
+	default:
+		err = fmt.Errorf("unknown author source %q", ref.source)
+	}
+	status := sourceStatus(ref.source, start, len(papers), err)
+	if err != nil {
+		fmt.Printf("%s author error: %v\n", ref.source, err)
+	}
+	return info, papers, status, err
+}
+
+// linkOpenAlexAuthor 在 OpenAlex 中查询作者论文的 DOI，按姓名匹配出其中的作者，返回多数论文一致的 OpenAlex ID
+func linkOpenAlexAuthor(ctx context.Context, author model.Author, papers []model.Paper) (string, error) {
+	var dois []string
+	for _, p := range papers {
+		if doi := provider.NormalizeDOI(p.DOI); doi != "" && len(dois) < maxLinkDOIs {
+			dois = append(dois, doi)
+		}
+	}
+	if len(dois) == 0 {
+		return "", nil
+	}
+	works, err := provider.FetchOpenAlexWorksByDOI(ctx, dois)
+	// 只按姓名匹配：S2 作者与 OpenAlex 作者没有共同的标识符
+	return voteAuthorID(model.Author{Name: author.Name}, works, func(a model.Author) string { return a.OpenAlexID }), err
+}
+
+// voteAuthorID 在每篇论文的作者中找到 author，返回出现最多的标识符（由 id 取得）。
+// 有两篇以上论文时至少需要两篇一致，避免同名作者的偶然匹配
+func voteAuthorID(author model.Author, papers []model.Paper, id func(model.Author) string) string {
+	votes := make(map[string]int)
+	best := ""
+	for _, p := range papers {
+		authors := provider.AuthorsOf(p)
+		i := provider.FindAuthor(authors, author)
+		if i < 0 {
+			continue
+		}
+		if v := id(authors[i]); v != "" {
+			votes[v]++
+			if votes[v] > votes[best] || votes[v] == votes[best] && v < best {
+				best = v
+			}
+		}
+	}
+	if best == "" || len(papers) >= 2 && votes[best] < 2 {
+		return ""
+	}
+	return best
+}
+
+// sourceStatus 构造单个数据源的执行状态
+func sourceStatus(source string, start time.Time, count int, err error) model.SourceStatus {
+	status := model.SourceStatus{
+		Source:    source,
+		Status:    model.SourceStatusOK,
+		LatencyMs: time.Since(start).Milliseconds(),
+		Count:     count,
+	}
+	if err != nil {
+		status.Status = model.SourceStatusError
+		if errors.Is(err, context.DeadlineExceeded) {
+			status.Status = model.SourceStatusTimeout
+		}
+		status.Error = err.Error()
+	}
+	return status
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+)
+
+// enrichers 是可通过 enrich 参数启用的补全阶段，按名称查找
+var enrichers = map[string]func(ctx context.Context, papers []model.Paper) (int, error){
+	"s2": provider.EnrichS2,
+}
+
+// enrichPapers 依次执行请求的补全阶段（原地修改 papers），返回各阶段的执行情况。
+// 补全结果同时写回本地论文库，以便离线与回退时仍可使用
+func enrichPapers(ctx context.Context, names []string, papers []model.Paper) []model.SourceStatus {
+	if len(names) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, requestDeadline)
+	defer cancel()
+
+	var statuses []model.SourceStatus
+	enriched := 0
+	seen := make(map[string]bool)
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		enrich, ok := enrichers[name]
+		if !ok {
+			statuses = append(statuses, model.SourceStatus{
+				Source: name,
+				Status: model.SourceStatusError,
+				Error:  "unknown enrichment",
+			})
+			continue
+		}
+		start := time.Now()
+		matched, err := enrich(ctx, papers)
+		status := model.SourceStatus{
+			Source:    name,
+			Status:    model.SourceStatusOK,
+			LatencyMs: time.Since(start).Milliseconds(),
+			Count:     matched,
+		}
+		if err != nil {
+			// 补全失败不影响主结果，仅在状态中说明
+			fmt.Printf("%s enrichment error: %v\n", name, err)
+			status.Status = model.SourceStatusError
+			if ctx.Err() != nil {
+				status.Status = model.SourceStatusTimeout
+			}
+			status.Error = err.Error()
+		}
+		enriched += matched
+		statuses = append(statuses, status)
+	}
+	if enriched > 0 {
+		ingestPapers(papers)
+	}
+	return statuses
+}
+
+// enrichNames 读取 enrich 参数（可重复或逗号分隔）；sort=citations 需要准确的引用量，自动启用 s2
+func enrichNames(values []string, sortOrder string) []string {
+	names := splitList(values)
+	if sortOrder == "citations" {
+		names = append(names, "s2")
+	}
+	return names
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"paper-scraper/internal/export"
+	"paper-scraper/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportPapers 以 BibTeX / RIS / CSL-JSON / CSV 格式导出论文。
+// 指定 ids 时从本地论文库按标识（arXiv ID、DOI 等）取论文，否则使用与 /search 相同的参数检索。
+// 部分 ids 未找到时仍导出其余论文，未找到的 ids 以逗号分隔写入 X-Missing-Ids 响应头
+func ExportPapers(c *gin.Context) {
+	format := strings.ToLower(c.DefaultQuery("format", export.FormatBibTeX))
+	f, ok := export.Formats[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("unsupported format %q", format)})
+		return
+	}
+
+	var papers []model.Paper
+	if ids := splitList(c.QueryArray("ids")); len(ids) > 0 {
+		if paperStore == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "paper store is not configured"})
+			return
+		}
+		var missing []string
+		for _, id := range ids {
+			rec, found, err := paperStore.Get(id)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+			if !found {
+				missing = append(missing, id)
+				continue
+			}
+			papers = append(papers, rec.Paper)
+		}
+		if len(papers) == 0 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "no papers found", "missing": missing})
+			return
+		}
+		if len(missing) > 0 {
+			c.Header("X-Missing-Ids", strings.Join(missing, ","))
+		}
+	} else {
+		resp, status, err := runSearch(c)
+		if err != nil {
+			c.JSON(status, gin.H{"error": err.Error(), "sources": resp.Sources})
+			return
+		}
+		if status != http.StatusOK {
+			c.JSON(status, resp)
+			return
+		}
+		papers = resp.Items
+	}
+
+	c.Header("Content-Type", f.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="scholarx-export.%s"`, f.Extension))
+	c.Status(http.StatusOK)
+	if err := export.Write(c.Writer, format, papers); err != nil {
+		fmt.Println("export error:", err)
+	}
+}
+
+// splitList 展开重复参数与逗号分隔的取值
+func splitList(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				out = append(out, item)
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/store"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestExportPapersByIDs(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "papers.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Upsert([]model.Paper{
+		{ID: "1", Source: "arxiv", ArxivID: "2401.00001", Title: "Energy-based models", Authors: []string{"Yann LeCun"}, PublishedAt: "2024-01-01"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	SetStore(s)
+	defer SetStore(nil)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/export", ExportPapers)
+
+	tests := []struct {
+		ids     string
+		status  int
+		missing string
+	}{
+		{"2401.00001", http.StatusOK, ""},
+		{"2401.00001,10.1000/nope,2401.99999", http.StatusOK, "10.1000/nope,2401.99999"},
+		{"2401.99999", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ids, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/export?format=csv&ids="+tt.ids, nil))
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.status, w.Body.String())
+			}
+			if got := w.Header().Get("X-Missing-Ids"); got != tt.missing {
+				t.Errorf("X-Missing-Ids = %q, want %q", got, tt.missing)
+			}
+			if tt.status == http.StatusOK && !strings.Contains(w.Body.String(), "lecun2024energy") {
+				t.Errorf("export missing the found paper:\n%s", w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"paper-scraper/internal/cache"
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+)
+
+// requestDeadline 是一次请求等待所有数据源的总时限，超时后返回已完成的数据源结果
+var requestDeadline = 20 * time.Second
+
+// SetRequestDeadline 设置请求总时限，非正值保持默认
+func SetRequestDeadline(d time.Duration) {
+	if d > 0 {
+		requestDeadline = d
+	}
+}
+
+type providerResult struct {
+	index  int
+	papers []model.Paper
+	status model.SourceStatus
+}
+
+// sourceCallback 在某个数据源返回结果（或超时）时被调用，用于流式推送。
+// 回调在调用方的 goroutine 中依次执行，无需加锁
+type sourceCallback func(status model.SourceStatus, papers []model.Paper)
+
+// fetchFromProviders 并发调用各数据源，合并返回的论文，
+// 并按调用顺序返回每个数据源的执行状态。
+// ctx 取消（客户端断开）或超过 requestDeadline 时立即返回已完成的数据源，
+// 未完成的数据源标记为 timeout，其上游请求随 ctx 一并取消
+func fetchFromProviders(ctx context.Context, providers []provider.Provider, q provider.Query) ([]model.Paper, []model.SourceStatus) {
+	return fetchEachProvider(ctx, providers, q, nil)
+}
+
+// fetchEachProvider 同 fetchFromProviders，onSource 非空时在每个数据源完成时调用
+func fetchEachProvider(ctx context.Context, providers []provider.Provider, q provider.Query, onSource sourceCallback) ([]model.Paper, []model.SourceStatus) {
+	ctx, cancel := context.WithTimeout(ctx, requestDeadline)
+	defer cancel()
+
+	start := time.Now()
+	results := make(chan providerResult, len(providers))
+	for i, p := range providers {
+		go func(i int, p provider.Provider) {
+			pctx, recorder := cache.WithRecorder(ctx)
+			papers, err := p.Search(pctx, q)
+
+			status := model.SourceStatus{
+				Source:    p.Name(),
+				Status:    model.SourceStatusOK,
+				LatencyMs: time.Since(start).Milliseconds(),
+				Count:     len(papers),
+			}
+			if cs := recorder.Status(); cs != cache.StatusBypass {
+				status.Cache = string(cs)
+			}
+			if err != nil {
+				fmt.Printf("%s error: %v\n", p.Name(), err)
+				status.Status = model.SourceStatusError
+				if ctx.Err() != nil {
+					status.Status = model.SourceStatusTimeout
+				}
+				status.Error = err.Error()
+				status.Count = 0
+				// 上游失败时退回到本地论文库中该来源此前保存的论文
+				if fallback, ok := searchStoreFallback(p.Name(), q); ok {
+					papers = fallback
+					status.Fallback = "store"
+					status.Count = len(papers)
+				}
+			} else if status.Cache != string(cache.StatusHit) && !p.Capabilities().Persistent {
+				// 命中缓存的结果此前已入库，本地数据源的结果本就来自论文库，都无需重复写入
+				ingestPapers(papers)
+			}
+			results <- providerResult{index: i, papers: papers, status: status}
+		}(i, p)
+	}
+
+	var allPapers []model.Paper
+	statuses := make([]model.SourceStatus, len(providers))
+	done := make([]bool, len(providers))
+	collect := func(r providerResult) {
+		statuses[r.index] = r.status
+		done[r.index] = true
+		allPapers = append(allPapers, r.papers...)
+		if onSource != nil {
+			onSource(r.status, r.papers)
+		}
+	}
+
+	remaining := len(providers)
+wait:
+	for remaining > 0 {
+		select {
+		case r := <-results:
+			collect(r)
+			remaining--
+		case <-ctx.Done():
+			break wait
+		}
+	}
+	// 收下与超时同时到达的结果
+drain:
+	for remaining > 0 {
+		select {
+		case r := <-results:
+			collect(r)
+			remaining--
+		default:
+			break drain
+		}
+	}
+
+	for i, p := range providers {
+		if done[i] {
+			continue
+		}
+		fmt.Printf("%s timeout: %v\n", p.Name(), ctx.Err())
+		status := model.SourceStatus{
+			Source:    p.Name(),
+			Status:    model.SourceStatusTimeout,
+			LatencyMs: time.Since(start).Milliseconds(),
+			Error:     ctx.Err().Error(),
+		}
+		var fallback []model.Paper
+		if papers, ok := searchStoreFallback(p.Name(), q); ok {
+			fallback = papers
+			allPapers = append(allPapers, fallback...)
+			status.Fallback = "store"
+			status.Count = len(fallback)
+		}
+		statuses[i] = status
+		if onSource != nil {
+			onSource(status, fallback)
+		}
+	}
+	return allPapers, statuses
+}
+
+// unknownSourceStatuses 为未注册的数据源名称生成错误状态
+func unknownSourceStatuses(names []string) []model.SourceStatus {
+	statuses := make([]model.SourceStatus, 0, len(names))
+	for _, name := range names {
+		statuses = append(statuses, model.SourceStatus{
+			Source: name,
+			Status: model.SourceStatusError,
+			Error:  "unknown source",
+		})
+	}
+	return statuses
+}
+
+// allSourcesFailed 判断是否所有请求的数据源都失败了（且没有本地结果可替代）
+func allSourcesFailed(statuses []model.SourceStatus) bool {
+	if len(statuses) == 0 {
+		return false
+	}
+	for _, s := range statuses {
+		if s.Status == model.SourceStatusOK || (s.Fallback != "" && s.Count > 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// cacheStatusHeader 汇总各数据源的缓存状态，写入 X-Cache-Status 响应头，
+// 例如 "arxiv=hit, openalex=miss"；没有数据源经过缓存时返回空串
+func cacheStatusHeader(statuses []model.SourceStatus) string {
+	var parts []string
+	for _, s := range statuses {
+		if s.Cache != "" {
+			parts = append(parts, s.Source+"="+s.Cache)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -1,111 +1,245 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
-	"sync"
 
 	"paper-scraper/internal/analysis"
+	"paper-scraper/internal/index"
+	"paper-scraper/internal/merge"
 	"paper-scraper/internal/model"
 	"paper-scraper/internal/pkg/translator"
 	"paper-scraper/internal/provider"
+	"paper-scraper/internal/query"
+	"paper-scraper/internal/store"
+	"paper-scraper/internal/summary"
 	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// 未指定 sources 时搜索使用的默认数据源
+var defaultSearchSources = []string{"arxiv", "openalex"}
+
+// 每日摘要的范围配置（分类、关键词、回溯窗口等），可通过 SetSummaryConfig 替换
+var summaryConfig = summary.DefaultConfig()
+
+// SetSummaryConfig 设置每日摘要的范围配置
+func SetSummaryConfig(cfg *summary.Config) {
+	if cfg != nil {
+		summaryConfig = cfg
+	}
+}
+
+// 本地论文库中的每日数据在该时长内视为新鲜，无需重新拉取
+const dailySummaryFreshness = 30 * time.Minute
+
+// 后台 harvest 任务（含 arXiv 全量摄取）写入的数据在该时长内视为新鲜
+const harvestFreshness = 2 * time.Hour
+
+// GetDailySummary 处理 /daily-summary。date 参数（YYYY-MM-DD，默认今天）选择日期：
+// 历史日期优先返回已保存的摘要，没有时按该日期的窗口拉取论文补算；今天的摘要超过新鲜期后重新计算。
+// 生成的摘要按范围与日期保存，供 /daily-summary/history 浏览
 func GetDailySummary(c *gin.Context) {
-	// 1. 确定“今天”（UTC 或本地时间）
-	now := time.Now()
-	// ArXiv 更新通常发生在夜间。为了确保“每日”摘要有内容，我们查看过去 48 小时的数据
-	// 或者如果需要严格性，可以专门针对“今天”。
-	// 在此演示中，我们将获取过去 2 天的数据以确保有内容展示。
-	// 格式：YYYY-MM-DD
-	today := now.Format("2006-01-02")
-	yesterday := now.AddDate(0, 0, -1).Format("2006-01-02")
-
-	// 2. 从提供商获取数据
-	// 我们将搜索广泛的类别以获得“前沿”概览。
-	// CS.AI, CS.CL (计算与语言), CS.CV (计算机视觉)
-	// queries := []string{"cat:cs.AI", "cat:cs.CL", "cat:cs.CV"} // 暂时未使用，因为我们使用广泛搜索
-
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	var allPapers []model.Paper
+	// 1. 确定摘要范围：profile 参数选择命名的配置，未指定时使用默认配置
+	profile, ok := summaryConfig.Profile(c.Query("profile"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown profile", "profiles": summaryConfig.Names()})
+		return
+	}
 
-	// 获取 ArXiv 数据（过去 2 天）
-	// 我们会获取多一点数据以确保有足够的分析样本
-	limit := 100
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		// 使用 "cs" 类别广泛搜索或特定子类别
-		// 我们将搜索 "Artificial Intelligence" 以确保覆盖面广且安全
-		// 每日摘要总是需要最新的论文，因此 sortOrder 为 "published_desc"（如果未更改签名，则为提供商默认值）
-		// 但我们更改了签名，所以需要传递它。
-		papers, err := provider.FetchArxiv("Artificial Intelligence", limit, 0, yesterday, today, "published_desc")
-		if err == nil {
-			mu.Lock()
-			allPapers = append(allPapers, papers...)
-			mu.Unlock()
-		}
-	}()
-
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		// OpenAlex
-		papers, err := provider.FetchOpenAlex("Artificial Intelligence", limit, 0, yesterday, today, "published_desc")
-		if err == nil {
-			mu.Lock()
-			allPapers = append(allPapers, papers...)
-			mu.Unlock()
+	// 2. 确定日期，格式：YYYY-MM-DD
+	today := time.Now().Format("2006-01-02")
+	date := c.DefaultQuery("date", today)
+	if _, err := time.Parse("2006-01-02", date); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid date, expected YYYY-MM-DD"})
+		return
+	}
+	if date > today {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date is in the future"})
+		return
+	}
+	// 历史日期的摘要不再变化；今天的摘要在新鲜期内（例如由后台任务预计算）直接返回
+	enrich := splitList(c.QueryArray("enrich"))
+	if saved, ok := loadSummary(profile.Name, date); ok {
+		if date != today || (len(enrich) == 0 && time.Since(saved.GeneratedAt) < dailySummaryFreshness) {
+			c.JSON(http.StatusOK, saved)
+			return
 		}
-	}()
+	}
 
-	wg.Wait()
+	daily := generateDailySummary(c.Request.Context(), profile, date, enrich)
+	if h := cacheStatusHeader(daily.Sources); h != "" {
+		c.Header("X-Cache-Status", h)
+	}
+	// 客户端已断开时不再返回与保存
+	if c.Request.Context().Err() != nil {
+		return
+	}
 
-	// 3. 分析
-	summary := analysis.AnalyzePapers(allPapers, today)
+	// 所有数据源都失败时返回 502，便于前端区分“今日无论文”与上游故障
+	if allSourcesFailed(daily.Sources) {
+		c.JSON(http.StatusBadGateway, daily)
+		return
+	}
+	saveSummary(daily)
+	c.JSON(http.StatusOK, daily)
+}
 
-	c.JSON(http.StatusOK, summary)
+// generateDailySummary 拉取以 date 结尾的窗口内的论文并生成该范围的每日摘要
+func generateDailySummary(ctx context.Context, profile *summary.Profile, date string, enrich []string) analysis.DailySummary {
+	// ArXiv 更新通常发生在夜间。为了确保“每日”摘要有内容，默认查看过去 2 天的数据
+	day, _ := time.Parse("2006-01-02", date)
+	startDate, endDate := profile.Window(day)
+
+	// 若本地论文库近期已摄取过该范围当天的数据（请求时拉取或后台 harvest 任务），
+	// 则直接基于存储计算，避免每次加载页面都重新拉取
+	var allPapers []model.Paper
+	var statuses []model.SourceStatus
+	ingestKey := "daily-summary:" + profile.Name + ":" + date
+	if paperStore != nil && (time.Since(paperStore.LastIngested(ingestKey)) < dailySummaryFreshness ||
+		time.Since(paperStore.LastIngested("harvest:"+profile.Name+":"+date)) < harvestFreshness) {
+		start := time.Now()
+		papers, err := paperStore.Search(store.Filter{
+			Sources:   profile.Sources,
+			StartDate: startDate,
+			EndDate:   endDate,
+			Match:     profile.Matches,
+		})
+		status := model.SourceStatus{Source: "store", Status: model.SourceStatusOK, Count: len(papers)}
+		if err != nil {
+			status.Status = model.SourceStatusError
+			status.Error = err.Error()
+		}
+		status.LatencyMs = time.Since(start).Milliseconds()
+		allPapers, statuses = papers, []model.SourceStatus{status}
+	} else {
+		// 按配置的分类、concept 与关键词拉取窗口内的最新论文，样本量由 sample_size 决定
+		providers, _ := provider.Resolve(profile.Sources)
+		allPapers, statuses = fetchFromProviders(ctx, providers, profile.Query(startDate, endDate))
+		if paperStore != nil && !allSourcesFailed(statuses) {
+			paperStore.MarkIngested(ingestKey, time.Now())
+		}
+	}
+	if ctx.Err() != nil {
+		return analysis.DailySummary{Date: date, Profile: profile.Name, Sources: statuses}
+	}
+
+	// 同一论文可能同时出现在 arXiv 与 OpenAlex 中，合并后再统计，避免每日计数虚高
+	allPapers = merge.Papers(allPapers)
+	enrichment := enrichPapers(ctx, enrich, allPapers)
+
+	// 分析
+	history := backgroundHistory(profile, startDate)
+	daily := analysis.AnalyzePapers(allPapers, date, analysis.Options{
+		Background:   history.Corpus(),
+		Institutions: summaryConfig.Institutions,
+	})
+	if history != nil {
+		trends := analysis.DetectTrends(history, analysis.NewTopicHistory(allPapers), startDate, endDate)
+		daily.Trends = &trends
+	}
+	daily.Profile = profile.Name
+	daily.GeneratedAt = time.Now().UTC()
+	daily.Sources = statuses
+	daily.Enrichment = enrichment
+	return daily
 }
 
+// statusClientClosedRequest 表示客户端在响应前断开连接（沿用 nginx 的 499）
+const statusClientClosedRequest = 499
+
 func SearchPapers(c *gin.Context) {
-	query := c.Query("query")
-	sourcesStr := c.DefaultQuery("sources", "arxiv,openalex")
+	resp, status, err := runSearch(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error(), "sources": resp.Sources})
+		return
+	}
+	c.JSON(status, resp)
+}
+
+// runSearch 执行 /search 的完整流程（解析参数、多源检索、去重、过滤与排序），
+// 返回响应体与 HTTP 状态码；参数错误时返回 error。/search 与 /export 共用
+func runSearch(c *gin.Context) (model.PaperResponse, int, error) {
+	req, status, err := parseSearchRequest(c)
+	if err != nil {
+		return model.PaperResponse{Sources: req.unknownStatuses()}, status, err
+	}
+	papers, statuses, nextCursor := req.fetch(c.Request.Context(), nil)
+	if h := cacheStatusHeader(statuses); h != "" {
+		c.Header("X-Cache-Status", h)
+	}
+
+	// 客户端已断开（例如无限滚动发起了更新的请求）时不再做后续处理
+	if err := c.Request.Context().Err(); err != nil {
+		return model.PaperResponse{Sources: statuses}, statusClientClosedRequest, err
+	}
+	resp, status := req.respond(c.Request.Context(), papers, statuses, nextCursor)
+	return resp, status, nil
+}
+
+// searchRequest 是解析后的 /search 参数，/search 与 /search/stream 共用
+type searchRequest struct {
+	keywords    string
+	translation string
+	sortOrder   string
+	limit       int
+	enrich      []string
+	providers   []provider.Provider
+	unknown     []string
+	query       provider.Query
+	keep        func(model.Paper) bool
+
+	// 分页：默认使用 cursor（next_cursor）；仅传 offset 时保留旧的偏移分页（next_offset）。
+	// sort=relevance 与 sort=citations 只能在已取得的结果上重新排序，无法跨页保持一致，总是使用偏移分页；
+	// 按发布时间排序但包含不能原生按时间排序的数据源（dblp、s2）时同理
+	useCursor bool
+	offset    int
+	cursor    *searchCursor
+}
+
+// parseSearchRequest 解析检索参数；参数错误时返回 400 与 error
+func parseSearchRequest(c *gin.Context) (*searchRequest, int, error) {
+	rawQuery := c.Query("query")
+	sourceNames := c.QueryArray("sources")
+	if len(sourceNames) == 0 {
+		sourceNames = defaultSearchSources
+	}
 	month := c.Query("month")
 	isTopTier := c.Query("top_tier") == "true"
 	ccfFilter := c.Query("ccf_level") // A, B, C, or empty
 	sortOrder := c.DefaultQuery("sort", "published_desc")
 
+	req := &searchRequest{sortOrder: sortOrder}
+
 	// 翻译逻辑
-	searchQuery := query
-	translation := ""
-	if translator.ContainsChinese(query) {
-		if trans, ok := translator.TranslateQuery(query); ok {
+	searchQuery := rawQuery
+	if translator.ContainsChinese(rawQuery) {
+		if trans, ok := translator.TranslateQuery(rawQuery); ok {
 			searchQuery = trans
-			translation = trans
+			req.translation = trans
 		}
 	}
 
+	cursorParam := c.Query("cursor")
+	_, hasOffset := c.GetQuery("offset")
+	rankedSort := sortOrder == "relevance" || sortOrder == "citations"
+	if cursorParam != "" && rankedSort {
+		return req, http.StatusBadRequest, errors.New("cursor pagination does not support sort=relevance or sort=citations, use offset")
+	}
+
 	offsetStr := c.DefaultQuery("offset", "0")
-	offset, _ := strconv.Atoi(offsetStr)
+	req.offset, _ = strconv.Atoi(offsetStr)
 
 	limitStr := c.DefaultQuery("limit", "20")
-	limit, _ := strconv.Atoi(limitStr)
-	if limit <= 0 {
-		limit = 20
-	}
-
-	sources := strings.Split(sourcesStr, ",")
-	sourcesSet := make(map[string]bool)
-	for _, s := range sources {
-		sourcesSet[s] = true
+	req.limit, _ = strconv.Atoi(limitStr)
+	if req.limit <= 0 {
+		req.limit = 20
 	}
 
 	// 日期范围
@@ -114,50 +248,141 @@ func SearchPapers(c *gin.Context) {
 		startDate, endDate = provider.GetMonthDateRange(month)
 	}
 
-	var wg sync.WaitGroup
-	var mu sync.Mutex
-	var allPapers []model.Paper
+	// 检索表达式，例如 author:"Yann LeCun" title:diffusion year:2023..2025 -survey
+	expr, err := query.Parse(searchQuery)
+	if err != nil {
+		return req, http.StatusBadRequest, fmt.Errorf("invalid query: %w", err)
+	}
+	req.keywords = query.Text(expr)
+	req.enrich = enrichNames(c.QueryArray("enrich"), sortOrder)
 
-	// 1. 获取数据
-
-	if sourcesSet["arxiv"] {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			papers, err := provider.FetchArxiv(searchQuery, limit, offset, startDate, endDate, sortOrder)
-			if err == nil {
-				mu.Lock()
-				allPapers = append(allPapers, papers...)
-				mu.Unlock()
-			} else {
-				fmt.Println("ArXiv error:", err)
+	// sources 参数解析为已注册的数据源
+	req.providers, req.unknown = provider.Resolve(sourceNames)
+	if len(req.providers) == 0 {
+		return req, http.StatusBadRequest, errors.New("no valid sources requested")
+	}
+	pageLocal := rankedSort
+	if sortOrder == "published_desc" || sortOrder == "published_asc" {
+		for _, p := range req.providers {
+			if p.Capabilities().Sort {
+				continue
 			}
-		}()
-	}
-
-	if sourcesSet["openalex"] {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			papers, err := provider.FetchOpenAlex(searchQuery, limit, offset, startDate, endDate, sortOrder)
-			if err == nil {
-				mu.Lock()
-				allPapers = append(allPapers, papers...)
-				mu.Unlock()
-			} else {
-				fmt.Println("OpenAlex error:", err)
+			if cursorParam != "" {
+				return req, http.StatusBadRequest, fmt.Errorf("cursor pagination with sort=%s requires sources sorted by date, %s is not, use offset", sortOrder, p.Name())
 			}
-		}()
+			pageLocal = true
+		}
+	}
+	req.useCursor = cursorParam != "" || (!hasOffset && !pageLocal)
+	req.query = provider.Query{
+		Text:      req.keywords,
+		Expr:      expr,
+		Limit:     req.limit,
+		Offset:    req.offset,
+		StartDate: startDate,
+		EndDate:   endDate,
+		Sort:      sortOrder,
 	}
+	req.keep = searchFilter(startDate, endDate, isTopTier, ccfFilter)
 
-	wg.Wait()
+	if req.useCursor {
+		names := make([]string, len(req.providers))
+		for i, p := range req.providers {
+			names[i] = p.Name()
+		}
+		sort.Strings(names)
+		fingerprint := cursorFingerprint(searchQuery, strings.Join(names, ","), sortOrder, month,
+			strconv.FormatBool(isTopTier), ccfFilter)
+		req.cursor, err = decodeCursor(cursorParam, fingerprint)
+		if err != nil {
+			return req, http.StatusBadRequest, err
+		}
+		req.query.Offset = 0
+	}
+	return req, http.StatusOK, nil
+}
+
+func (r *searchRequest) unknownStatuses() []model.SourceStatus {
+	if r == nil {
+		return nil
+	}
+	return unknownSourceStatuses(r.unknown)
+}
+
+// fetch 从各数据源获取数据。游标分页在归并时已完成去重与过滤，结果按各来源的顺序归并；
+// 偏移分页返回去重后、尚未过滤的结果。onSource 非空时在每个数据源返回首批结果时调用
+func (r *searchRequest) fetch(ctx context.Context, onSource sourceCallback) ([]model.Paper, []model.SourceStatus, string) {
+	if r.useCursor {
+		return paginate(ctx, r.providers, r.query, r.cursor, r.keep, r.limit, onSource)
+	}
+	allPapers, statuses := fetchEachProvider(ctx, r.providers, r.query, onSource)
+	// 跨来源去重：同一论文的 arXiv 预印本与 OpenAlex 记录合并为一条
+	return merge.Papers(allPapers), statuses, ""
+}
+
+// respond 对获取到的论文做补全、过滤与排序，生成响应体与 HTTP 状态码
+func (r *searchRequest) respond(ctx context.Context, papers []model.Paper, statuses []model.SourceStatus, nextCursor string) (model.PaperResponse, int) {
+	// 可选补全：enrich=s2 从 Semantic Scholar 回填引用量、venue 与外部 ID
+	enrichment := enrichPapers(ctx, r.enrich, papers)
+
+	// 过滤与排序
+	filtered := papers
+	if !r.useCursor {
+		filtered = nil
+		for _, p := range papers {
+			if r.keep(p) {
+				filtered = append(filtered, p)
+			}
+		}
+	}
 
-	// 1.5 获取 ArXiv 论文的引用
-	// 引用量数据不可捕获，故去除相关逻辑
+	// relevance 与 citations 只对本页（偏移分页取得的合并结果）排序
+	switch r.sortOrder {
+	case "relevance":
+		// 各来源的相关度排序标准不一，在合并结果上统一用 BM25 重新排序
+		filtered = index.Rank(r.keywords, filtered)
+	case "citations":
+		// 引用量降序，相同时较新的在前
+		sort.SliceStable(filtered, func(i, j int) bool {
+			if filtered[i].Citations != filtered[j].Citations {
+				return filtered[i].Citations > filtered[j].Citations
+			}
+			return filtered[i].PublishedAt > filtered[j].PublishedAt
+		})
+	default:
+		// 按日期排序（游标分页的归并结果已有序）
+		if !r.useCursor {
+			sort.Slice(filtered, func(i, j int) bool {
+				if r.sortOrder == "published_asc" {
+					return filtered[i].PublishedAt < filtered[j].PublishedAt
+				}
+				return filtered[i].PublishedAt > filtered[j].PublishedAt
+			})
+		}
+	}
 
-	// 2. 过滤与排序
-	var filtered []model.Paper
+	statuses = append(statuses, unknownSourceStatuses(r.unknown)...)
+	resp := model.PaperResponse{
+		Count:       len(filtered),
+		Items:       filtered,
+		Translation: r.translation,
+		Sources:     statuses,
+		Enrichment:  enrichment,
+		NextCursor:  nextCursor,
+	}
+	if !r.useCursor && len(papers) > 0 {
+		next := r.offset + r.limit
+		resp.NextOffset = &next
+	}
+	// 仅当所有请求的数据源都失败时才返回 502，部分失败时正常返回并在 sources 中说明
+	if allSourcesFailed(statuses) {
+		return resp, http.StatusBadGateway
+	}
+	return resp, http.StatusOK
+}
 
+// searchFilter 返回 /search 在合并结果上应用的严格过滤条件：日期范围、顶会/顶刊与 CCF 等级
+func searchFilter(startDate, endDate string, isTopTier bool, ccfFilter string) func(model.Paper) bool {
 	// 日期解析以进行严格过滤
 	var startT, endT time.Time
 	if startDate != "" && endDate != "" {
@@ -167,14 +392,14 @@ func SearchPapers(c *gin.Context) {
 		endT = endT.Add(24*time.Hour - time.Nanosecond)
 	}
 
-	for _, p := range allPapers {
+	return func(p model.Paper) bool {
 		// 严格日期过滤器（修复“月份精度”问题）
 		if !startT.IsZero() && !endT.IsZero() {
 			// PublishedAt 是字符串 ISO 或 YYYY-MM-DD
 			pubT := provider.ParseDate(p.PublishedAt)
 			if !pubT.IsZero() {
 				if pubT.Before(startT) || pubT.After(endT) {
-					continue
+					return false
 				}
 			}
 		}
@@ -190,32 +415,16 @@ func SearchPapers(c *gin.Context) {
 				}
 			}
 			if !isTop {
-				continue
+				return false
 			}
 		}
 
 		// CCF 过滤器
 		if ccfFilter != "" {
 			if p.CCFClass != ccfFilter {
-				continue
+				return false
 			}
 		}
-
-		filtered = append(filtered, p)
+		return true
 	}
-
-	// 按日期降序排序
-	sort.Slice(filtered, func(i, j int) bool {
-		if sortOrder == "published_asc" {
-			return filtered[i].PublishedAt < filtered[j].PublishedAt
-		}
-		// 引用量排序已移除
-		return filtered[i].PublishedAt > filtered[j].PublishedAt
-	})
-
-	c.JSON(http.StatusOK, model.PaperResponse{
-		Count:       len(filtered),
-		Items:       filtered,
-		Translation: translation,
-	})
 }
@@ -0,0 +1,149 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+	"paper-scraper/internal/store"
+	"paper-scraper/internal/summary"
+)
+
+const (
+	// citationRefreshWindow 是引用量刷新覆盖的发布时间范围，较早论文的引用量变化较慢
+	citationRefreshWindow = 30 * 24 * time.Hour
+	// citationRefreshLimit 是单次刷新的论文数上限
+	citationRefreshLimit = 2000
+)
+
+// 以下为后台调度器运行的任务（见 internal/scheduler），返回一句结果说明
+
+// arxivHarvestEnabled 控制 harvest 任务是否通过 OAI-PMH 全量摄取 arXiv
+var arxivHarvestEnabled = true
+
+// arxivHarvestSet 是 OAI-PMH 摄取的 arXiv 集合
+const arxivHarvestSet = "cs"
+
+// SetArxivHarvest 启用或禁用 arXiv OAI-PMH 全量摄取；禁用时 harvest 任务改用检索接口抽样
+func SetArxivHarvest(enabled bool) {
+	arxivHarvestEnabled = enabled
+}
+
+// HarvestPapers 为每个摘要范围拉取回溯窗口内的最新论文并写入本地论文库，
+// 之后生成的当天摘要直接基于本地库计算。arXiv 通过 OAI-PMH 全量摄取，
+// 成功时各范围不再通过检索接口拉取 arXiv，摘要因此覆盖当天的全部新论文
+func HarvestPapers(ctx context.Context) (string, error) {
+	today := time.Now().Format("2006-01-02")
+	var errs []error
+	var results []string
+
+	oaiDone := false
+	if arxivHarvestEnabled && paperStore != nil {
+		stats, err := harvestArxivOAI(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("arxiv oai-pmh: %w", err))
+		} else {
+			oaiDone = true
+		}
+		results = append(results, fmt.Sprintf("oai-pmh %d papers in %d pages", stats.Papers, stats.Pages))
+	}
+
+	total := 0
+	for _, name := range summaryConfig.Names() {
+		profile, _ := summaryConfig.Profile(name)
+		var sources []string
+		for _, s := range profile.Sources {
+			if !(oaiDone && s == "arxiv") {
+				sources = append(sources, s)
+			}
+		}
+		if len(sources) > 0 {
+			startDate, endDate := profile.Window(time.Now())
+			providers, _ := provider.Resolve(sources)
+			_, statuses := fetchFromProviders(ctx, providers, profile.Query(startDate, endDate))
+			if allSourcesFailed(statuses) {
+				errs = append(errs, fmt.Errorf("%s: all sources failed", name))
+				continue
+			}
+			for _, s := range statuses {
+				if s.Status == model.SourceStatusOK {
+					total += s.Count
+				}
+			}
+		}
+		if paperStore != nil {
+			paperStore.MarkIngested("harvest:"+name+":"+today, time.Now())
+		}
+	}
+	results = append(results, fmt.Sprintf("fetched %d papers for %d profiles", total, len(summaryConfig.Profiles)))
+	return strings.Join(results, "; "), errors.Join(errs...)
+}
+
+// harvestArxivOAI 从上次成功摄取的日期起（首次为最长的摘要窗口）摄取 arXiv cs 集合的全部记录。
+// OAI-PMH 的日期粒度为天，因此从上次摄取的当天重新开始，以补上当天稍晚发布的记录
+func harvestArxivOAI(ctx context.Context) (provider.HarvestStats, error) {
+	checkpoint := "oai:arxiv:" + arxivHarvestSet
+	from := paperStore.LastIngested(checkpoint)
+	if from.IsZero() {
+		days := summary.DefaultWindowDays
+		for _, p := range summaryConfig.Profiles {
+			days = max(days, p.WindowDays)
+		}
+		from = time.Now().AddDate(0, 0, -days)
+	}
+	started := time.Now()
+	stats, err := provider.HarvestArxiv(ctx, arxivHarvestSet, from.UTC().Format("2006-01-02"), "", func(papers []model.Paper) error {
+		_, err := paperStore.Upsert(papers)
+		return err
+	})
+	if err != nil {
+		return stats, err
+	}
+	paperStore.MarkIngested(checkpoint, started)
+	return stats, nil
+}
+
+// RefreshCitations 通过 Semantic Scholar 刷新本地库中近期论文的引用量
+func RefreshCitations(ctx context.Context) (string, error) {
+	if paperStore == nil {
+		return "", errors.New("no local store")
+	}
+	papers, err := paperStore.Search(store.Filter{
+		StartDate: time.Now().Add(-citationRefreshWindow).Format("2006-01-02"),
+		Sort:      "published_desc",
+		Limit:     citationRefreshLimit,
+	})
+	if err != nil {
+		return "", err
+	}
+	matched, err := provider.EnrichS2(ctx, papers)
+	if matched > 0 {
+		ingestPapers(papers)
+	}
+	return fmt.Sprintf("refreshed %d of %d papers", matched, len(papers)), err
+}
+
+// PrecomputeSummaries 为每个摘要范围生成并保存当天的摘要，使 /daily-summary 无需等待上游
+func PrecomputeSummaries(ctx context.Context) (string, error) {
+	today := time.Now().Format("2006-01-02")
+	done := 0
+	var errs []error
+	for _, name := range summaryConfig.Names() {
+		profile, _ := summaryConfig.Profile(name)
+		daily := generateDailySummary(ctx, profile, today, nil)
+		if ctx.Err() != nil {
+			return fmt.Sprintf("precomputed %d summaries", done), ctx.Err()
+		}
+		if allSourcesFailed(daily.Sources) {
+			errs = append(errs, fmt.Errorf("%s: all sources failed", name))
+			continue
+		}
+		saveSummary(daily)
+		done++
+	}
+	return fmt.Sprintf("precomputed %d summaries", done), errors.Join(errs...)
+}
@@ -0,0 +1,414 @@
+package api
+
+import (
+	"bytes"
+	"compress/flate"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"paper-scraper/internal/cache"
+	"paper-scraper/internal/merge"
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+)
+
+// maxPageFetches 是一次请求中每个数据源最多拉取的上游页数。
+// 过滤条件很严格时宁可返回不满一页的结果（附带 next_cursor），也不无限制地访问上游
+const maxPageFetches = 4
+
+// sourceCursor 是单个数据源的分页位置：Pos 为当前上游页的位置（arXiv start、
+// OpenAlex cursor 等），Skip 为该页中已消费的条数。
+// Store 表示该数据源首页失败后改为读取本地论文库，此时 Pos 为论文库中的偏移量
+type sourceCursor struct {
+	Pos   string `json:"p,omitempty"`
+	Skip  int    `json:"k,omitempty"`
+	Done  bool   `json:"d,omitempty"`
+	Store bool   `json:"f,omitempty"`
+}
+
+// searchCursor 是 next_cursor 的内容，对客户端不透明
+type searchCursor struct {
+	Query   string                   `json:"q"`           // 查询参数指纹，防止游标用于其他查询
+	Sources map[string]*sourceCursor `json:"s"`           // 各数据源的位置
+	Seen    seenFilter               `json:"n,omitempty"` // 此前各页已消费论文的匹配键，用于跨页去重
+}
+
+var errCursorMismatch = errors.New("cursor does not match query")
+
+// cursorFingerprint 由影响结果集的查询参数生成指纹
+func cursorFingerprint(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x1f")))
+	return hex.EncodeToString(sum[:8])
+}
+
+func encodeCursor(c *searchCursor) string {
+	raw, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// decodeCursor 解析客户端传回的游标；raw 为空时返回第一页的初始状态
+func decodeCursor(raw, fingerprint string) (*searchCursor, error) {
+	if raw == "" {
+		return &searchCursor{Query: fingerprint, Sources: make(map[string]*sourceCursor)}, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	var c searchCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cursor: %w", err)
+	}
+	if c.Query != fingerprint {
+		return nil, errCursorMismatch
+	}
+	if c.Sources == nil {
+		c.Sources = make(map[string]*sourceCursor)
+	}
+	return &c, nil
+}
+
+// 游标中已消费论文匹配键的布隆过滤器参数：16384 位、4 个哈希，
+// 记录约 2000 个键（数十页）时误判率仍低于 2%，游标长度不随页数增长
+const (
+	seenFilterBits   = 1 << 14
+	seenFilterHashes = 4
+)
+
+// seenFilter 是记录已消费论文匹配键的布隆过滤器。
+// 跨数据源的同一论文发布日期常常不同，可能相隔多页才出现，因此需要累计此前所有页的键
+type seenFilter []byte
+
+func (f seenFilter) positions(key string) [seenFilterHashes]uint32 {
+	sum := sha256.Sum256([]byte(key))
+	h1 := binary.BigEndian.Uint32(sum[0:4])
+	h2 := binary.BigEndian.Uint32(sum[4:8]) | 1
+	var pos [seenFilterHashes]uint32
+	for i := range pos {
+		pos[i] = (h1 + uint32(i)*h2) % seenFilterBits
+	}
+	return pos
+}
+
+func (f seenFilter) has(key string) bool {
+	if len(f) == 0 {
+		return false
+	}
+	for _, p := range f.positions(key) {
+		if f[p/8]&(1<<(p%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// add 记录 key，f 为空时分配位图
+func (f *seenFilter) add(key string) {
+	if len(*f) == 0 {
+		*f = make(seenFilter, seenFilterBits/8)
+	}
+	for _, p := range f.positions(key) {
+		(*f)[p/8] |= 1 << (p % 8)
+	}
+}
+
+// MarshalJSON 压缩位图后编码，前几页的位图很稀疏，压缩后只有几十字节
+func (f seenFilter) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(f); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return json.Marshal(buf.Bytes())
+}
+
+func (f *seenFilter) UnmarshalJSON(data []byte) error {
+	var compressed []byte
+	if err := json.Unmarshal(data, &compressed); err != nil {
+		return err
+	}
+	bits, err := io.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), seenFilterBits/8+1))
+	if err != nil {
+		return err
+	}
+	if len(bits) != seenFilterBits/8 {
+		return errors.New("invalid seen filter")
+	}
+	*f = bits
+	return nil
+}
+
+// pageStream 是单个数据源按分页位置展开的论文流
+type pageStream struct {
+	provider provider.Provider
+	state    *sourceCursor
+	buf      []model.Paper // 当前上游页中尚未消费的论文
+	next     string        // 当前上游页之后的位置
+	loaded   bool
+	fetches  int
+	failed   bool
+	status   model.SourceStatus
+}
+
+func (s *pageStream) active() bool {
+	return !s.state.Done && !s.failed
+}
+
+// load 拉取当前位置的上游页，并跳过此前已消费的条数。
+// 数据源在首页失败时退回到本地论文库中该来源此前保存的论文，之后各页继续读取论文库
+func (s *pageStream) load(ctx context.Context, q provider.Query) {
+	s.fetches++
+	if s.state.Store {
+		s.loadStore(q)
+		return
+	}
+	start := time.Now()
+	pctx, recorder := cache.WithRecorder(ctx)
+	page, err := provider.SearchPage(pctx, s.provider, q, s.state.Pos)
+	s.status.LatencyMs += time.Since(start).Milliseconds()
+	cacheStatus := recorder.Status()
+	if cacheStatus != cache.StatusBypass {
+		s.status.Cache = string(cacheStatus)
+	}
+	if err != nil {
+		fmt.Printf("%s error: %v\n", s.provider.Name(), err)
+		s.status.Status = model.SourceStatusError
+		if ctx.Err() != nil {
+			s.status.Status = model.SourceStatusTimeout
+		}
+		s.status.Error = err.Error()
+		if s.state.Pos != "" || s.state.Skip != 0 || s.loadStore(q) == 0 {
+			s.failed = true
+		}
+		return
+	}
+	if cacheStatus != cache.StatusHit && !s.provider.Capabilities().Persistent {
+		ingestPapers(page.Papers)
+	}
+
+	s.buf = nil
+	if s.state.Skip < len(page.Papers) {
+		s.buf = page.Papers[s.state.Skip:]
+	}
+	s.next = page.Next
+	s.loaded = true
+	s.status.Count += len(s.buf)
+}
+
+// loadStore 从本地论文库读取该来源在偏移量 Pos 处的一页，返回读到的条数
+func (s *pageStream) loadStore(q provider.Query) int {
+	offset, _ := strconv.Atoi(s.state.Pos)
+	q.Offset = offset
+	papers, _ := searchStoreFallback(s.provider.Name(), q)
+	if len(papers) > 0 {
+		s.state.Store = true
+		s.status.Fallback = "store"
+	}
+	s.buf = nil
+	if s.state.Skip < len(papers) {
+		s.buf = papers[s.state.Skip:]
+	}
+	s.next = ""
+	if len(papers) == q.Limit {
+		s.next = strconv.Itoa(offset + len(papers))
+	}
+	s.loaded = true
+	s.status.Count += len(s.buf)
+	return len(papers)
+}
+
+// advance 在当前上游页消费完后移动到下一页
+func (s *pageStream) advance() {
+	if s.next == "" {
+		s.state.Done = true
+	} else {
+		s.state.Pos = s.next
+		s.state.Skip = 0
+	}
+	s.buf, s.next, s.loaded = nil, "", false
+}
+
+func (s *pageStream) pop() model.Paper {
+	p := s.buf[0]
+	s.buf = s.buf[1:]
+	s.state.Skip++
+	if len(s.buf) == 0 {
+		s.advance()
+	}
+	return p
+}
+
+// paginate 从 cursor 记录的位置开始对各数据源做 k 路归并，去重并过滤后凑满 limit 条。
+// 只有当每个仍有结果的数据源都有待消费的论文时才选出下一条，分页之间没有遗漏；
+// 游标累计记录此前各页消费过的论文（包括被过滤掉的），其他数据源的同一论文在之后的页中不再出现。
+// 记录使用布隆过滤器，页数很多时有极小概率误判而跳过一篇论文。
+// 按发布时间排序时依次取各数据源队首中最新（或最早）的一条，要求各数据源原生按时间排序
+// （见 parseSearchRequest）；其他排序方式轮流取各数据源的结果
+func paginate(ctx context.Context, providers []provider.Provider, q provider.Query, cur *searchCursor,
+	keep func(model.Paper) bool, limit int, onSource sourceCallback) ([]model.Paper, []model.SourceStatus, string) {
+	ctx, cancel := context.WithTimeout(ctx, requestDeadline)
+	defer cancel()
+
+	streams := make([]*pageStream, len(providers))
+	for i, p := range providers {
+		state, ok := cur.Sources[p.Name()]
+		if !ok {
+			state = &sourceCursor{}
+			cur.Sources[p.Name()] = state
+		}
+		streams[i] = &pageStream{
+			provider: p,
+			state:    state,
+			status:   model.SourceStatus{Source: p.Name(), Status: model.SourceStatusOK},
+		}
+	}
+
+	// 首批上游页并发拉取，按完成顺序回调
+	loaded := make(chan *pageStream, len(streams))
+	pending := 0
+	for _, s := range streams {
+		if s.active() {
+			pending++
+			go func(s *pageStream) {
+				s.load(ctx, q)
+				loaded <- s
+			}(s)
+		}
+	}
+	for ; pending > 0; pending-- {
+		s := <-loaded
+		if onSource != nil {
+			onSource(s.status, s.buf)
+		}
+	}
+
+	var page []model.Paper
+	pageKeys := make(map[string]int)
+	rejected := make(map[string]bool) // 本页被过滤掉的论文的匹配键
+	turn := 0
+
+fill:
+	for len(page) < limit {
+		// 确保每个仍有结果的数据源都有待消费的论文
+		for _, s := range streams {
+			for s.active() && len(s.buf) == 0 {
+				if s.loaded {
+					s.advance()
+					continue
+				}
+				if ctx.Err() != nil || s.fetches >= maxPageFetches {
+					break fill
+				}
+				s.load(ctx, q)
+			}
+		}
+
+		best := -1
+		for n := 0; n < len(streams); n++ {
+			i := (turn + n) % len(streams)
+			s := streams[i]
+			if !s.active() || len(s.buf) == 0 {
+				continue
+			}
+			if best < 0 {
+				best = i
+				if q.Sort != "published_desc" && q.Sort != "published_asc" {
+					break
+				}
+				continue
+			}
+			// 各来源的日期格式不同（2024-01-02、2024-01-02T15:04:05Z 等），解析后比较
+			head, bestHead := provider.ParseDate(s.buf[0].PublishedAt), provider.ParseDate(streams[best].buf[0].PublishedAt)
+			if (q.Sort == "published_asc" && head.Before(bestHead)) || (q.Sort == "published_desc" && head.After(bestHead)) {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		if q.Sort != "published_desc" && q.Sort != "published_asc" {
+			turn = best + 1
+		}
+		p := streams[best].pop()
+
+		keys := merge.Keys(p)
+		duplicate := false
+		for _, k := range keys {
+			if cur.Seen.has(k) || rejected[k] {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+		// 与本页已有论文是同一篇时融合为一条
+		merged := false
+		for _, k := range keys {
+			if i, ok := pageKeys[k]; ok {
+				page[i] = merge.Fuse([]model.Paper{page[i], p})
+				for _, k := range keys {
+					pageKeys[k] = i
+				}
+				merged = true
+				break
+			}
+		}
+		if merged {
+			continue
+		}
+		if !keep(p) {
+			for _, k := range keys {
+				rejected[k] = true
+			}
+			continue
+		}
+		if len(p.Sources) == 0 {
+			p.Sources = []string{p.Source}
+		}
+		for _, k := range keys {
+			pageKeys[k] = len(page)
+		}
+		page = append(page, p)
+	}
+
+	statuses := make([]model.SourceStatus, len(streams))
+	remaining := false
+	for i, s := range streams {
+		statuses[i] = s.status
+		if !s.state.Done {
+			remaining = true
+		}
+	}
+
+	next := ""
+	if remaining {
+		for k := range pageKeys {
+			cur.Seen.add(k)
+		}
+		for k := range rejected {
+			cur.Seen.add(k)
+		}
+		next = encodeCursor(cur)
+	}
+	return page, statuses, next
+}
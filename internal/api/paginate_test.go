@@ -0,0 +1,220 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+	"paper-scraper/internal/store"
+)
+
+// fakeProvider 按偏移量返回固定的论文列表（已按发布时间从新到旧排列）
+type fakeProvider struct {
+	name   string
+	papers []model.Paper
+}
+
+func (f fakeProvider) Name() string { return f.name }
+
+func (f fakeProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{DateRange: true, Sort: true, Offset: true}
+}
+
+func (f fakeProvider) Search(ctx context.Context, q provider.Query) ([]model.Paper, error) {
+	if q.Offset >= len(f.papers) {
+		return nil, nil
+	}
+	end := min(q.Offset+q.Limit, len(f.papers))
+	return f.papers[q.Offset:end], nil
+}
+
+func fakePaper(source string, n int, published string) model.Paper {
+	id := fmt.Sprintf("2401.%05d", n)
+	return model.Paper{
+		ID:          source + ":" + id,
+		Title:       fmt.Sprintf("Paper number %d", n),
+		Authors:     []string{"Ada Lovelace"},
+		Source:      source,
+		ArxivID:     id,
+		PublishedAt: published,
+	}
+}
+
+func TestPaginateCrossPageDedup(t *testing.T) {
+	// a 中的论文按 arXiv 日期排列；b 是同一批论文，但发布日期晚一个月以上（正式发表），
+	// 按发布时间归并时要在多页之后才出现
+	var a, b []model.Paper
+	for n := 10; n >= 1; n-- {
+		a = append(a, fakePaper("a", n, fmt.Sprintf("2024-01-%02d", n)))
+	}
+	for n := 10; n >= 1; n-- {
+		b = append(b, fakePaper("b", n, fmt.Sprintf("2024-03-%02d", n)))
+	}
+	// b 独有的一篇
+	b = append(b, fakePaper("b", 99, "2024-02-15"))
+	providers := []provider.Provider{fakeProvider{name: "a", papers: a}, fakeProvider{name: "b", papers: b}}
+
+	// reject 为先出现 3 号论文的数据源：该副本被过滤掉后，另一个数据源的副本也不应出现
+	tests := []struct {
+		name   string
+		sort   string
+		reject string
+	}{
+		{"published_desc", "published_desc", "b"},
+		{"published_asc", "published_asc", "a"},
+		{"round_robin", "", "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keep := func(p model.Paper) bool {
+				return !(p.Source == tt.reject && p.ArxivID == "2401.00003")
+			}
+			q := provider.Query{Limit: 3, Sort: tt.sort}
+			fingerprint := cursorFingerprint("test", tt.sort)
+			counts := make(map[string]int)
+			raw := ""
+			for pages := 0; ; pages++ {
+				if pages > 20 {
+					t.Fatal("pagination did not terminate")
+				}
+				cur, err := decodeCursor(raw, fingerprint)
+				if err != nil {
+					t.Fatalf("decodeCursor: %v", err)
+				}
+				page, _, next := paginate(context.Background(), providers, q, cur, keep, q.Limit, nil)
+				for _, p := range page {
+					counts[p.ArxivID]++
+				}
+				if next == "" {
+					break
+				}
+				raw = next
+			}
+
+			for n := 1; n <= 10; n++ {
+				id := fmt.Sprintf("2401.%05d", n)
+				want := 1
+				if n == 3 {
+					want = 0
+				}
+				if counts[id] != want {
+					t.Errorf("paper %s returned %d times, want %d", id, counts[id], want)
+				}
+			}
+			if counts["2401.00099"] != 1 {
+				t.Errorf("paper only in b returned %d times, want 1", counts["2401.00099"])
+			}
+		})
+	}
+}
+
+func TestDecodeCursor(t *testing.T) {
+	cur := &searchCursor{Query: "fp", Sources: map[string]*sourceCursor{"a": {Pos: "20", Skip: 3}}}
+	cur.Seen.add("arxiv:2401.00001")
+	raw := encodeCursor(cur)
+
+	got, err := decodeCursor(raw, "fp")
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if got.Sources["a"].Pos != "20" || got.Sources["a"].Skip != 3 {
+		t.Errorf("source cursor = %+v, want {Pos:20 Skip:3}", got.Sources["a"])
+	}
+	if !got.Seen.has("arxiv:2401.00001") {
+		t.Error("seen filter lost a recorded key")
+	}
+	if got.Seen.has("arxiv:2401.00002") {
+		t.Error("seen filter reports an unrecorded key")
+	}
+
+	if _, err := decodeCursor(raw, "other"); err != errCursorMismatch {
+		t.Errorf("decodeCursor with other fingerprint: err = %v, want %v", err, errCursorMismatch)
+	}
+	for _, bad := range []string{"!!!", "bm90IGpzb24"} {
+		if _, err := decodeCursor(bad, "fp"); err == nil || !strings.Contains(err.Error(), "invalid cursor") {
+			t.Errorf("decodeCursor(%q): err = %v, want invalid cursor", bad, err)
+		}
+	}
+}
+
+func TestPaginateComparesParsedDates(t *testing.T) {
+	// a 的时间带时区偏移：2024-01-05T23:00:00-05:00 实际晚于 b 的 2024-01-06T01:00:00Z
+	a := []model.Paper{fakePaper("a", 1, "2024-01-05T23:00:00-05:00"), fakePaper("a", 2, "2024-01-03")}
+	b := []model.Paper{fakePaper("b", 3, "2024-01-06T01:00:00Z"), fakePaper("b", 4, "2024-01-04")}
+	providers := []provider.Provider{fakeProvider{name: "a", papers: a}, fakeProvider{name: "b", papers: b}}
+
+	q := provider.Query{Limit: 4, Sort: "published_desc"}
+	cur, _ := decodeCursor("", "fp")
+	page, _, _ := paginate(context.Background(), providers, q, cur, func(model.Paper) bool { return true }, q.Limit, nil)
+	var got []string
+	for _, p := range page {
+		got = append(got, p.ArxivID)
+	}
+	want := []string{"2401.00001", "2401.00003", "2401.00004", "2401.00002"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("order = %v, want %v", got, want)
+	}
+}
+
+// failingProvider 总是返回错误
+type failingProvider struct{ name string }
+
+func (f failingProvider) Name() string { return f.name }
+func (f failingProvider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{Sort: true}
+}
+
+func (f failingProvider) Search(ctx context.Context, q provider.Query) ([]model.Paper, error) {
+	return nil, errors.New("upstream unavailable")
+}
+
+func TestPaginateStoreFallback(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "papers.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	var stored []model.Paper
+	for n := 1; n <= 5; n++ {
+		stored = append(stored, fakePaper("down", n, fmt.Sprintf("2024-01-%02d", n)))
+	}
+	if _, err := s.Upsert(stored); err != nil {
+		t.Fatal(err)
+	}
+	SetStore(s)
+	defer SetStore(nil)
+
+	providers := []provider.Provider{failingProvider{name: "down"}}
+	q := provider.Query{Limit: 2, Sort: "published_desc"}
+	var got []string
+	raw := ""
+	for pages := 0; ; pages++ {
+		if pages > 10 {
+			t.Fatal("pagination did not terminate")
+		}
+		cur, err := decodeCursor(raw, "fp")
+		if err != nil {
+			t.Fatalf("decodeCursor: %v", err)
+		}
+		page, statuses, next := paginate(context.Background(), providers, q, cur, func(model.Paper) bool { return true }, q.Limit, nil)
+		if statuses[0].Fallback != "store" {
+			t.Errorf("page %d: status = %+v, want store fallback", pages, statuses[0])
+		}
+		for _, p := range page {
+			got = append(got, p.ArxivID)
+		}
+		if next == "" {
+			break
+		}
+		raw = next
+	}
+	want := []string{"2401.00005", "2401.00004", "2401.00003", "2401.00002", "2401.00001"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("papers = %v, want %v", got, want)
+	}
+}
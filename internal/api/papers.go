@@ -0,0 +1,399 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"paper-scraper/internal/cache"
+	"paper-scraper/internal/merge"
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// paperCacheTTL 是论文详情（论文与参考文献）的缓存有效期
+	paperCacheTTL = 6 * time.Hour
+	// citingCacheTTL 是被引列表每一页的缓存有效期
+	citingCacheTTL = time.Hour
+	// maxReferences 是每个数据源读取的参考文献数上限
+	maxReferences = 200
+	// defaultCitingLimit 与 maxCitingLimit 是被引列表每页条数的默认值与上限
+	defaultCitingLimit = 20
+	maxCitingLimit     = 100
+)
+
+var (
+	// 不带前缀的 arXiv ID：新格式 2106.12345 与旧格式 cs/0112017，可带版本号
+	arxivNewIDPattern = regexp.MustCompile(`^\d{4}\.\d{4,5}(v\d+)?$`)
+	arxivOldIDPattern = regexp.MustCompile(`^[a-z-]+(\.[A-Z]{2})?/\d{7}(v\d+)?$`)
+	// OpenAlex 论文 ID（W2741809807）与 S2 paperId（40 位十六进制）
+	openAlexWorkIDPattern = regexp.MustCompile(`(?i)^W\d+$`)
+	s2PaperIDPattern      = regexp.MustCompile(`(?i)^[0-9a-f]{40}$`)
+)
+
+// paperRef 是论文在各库中的标识，解析请求时至少有一个非空，其余在查询过程中补全
+type paperRef struct {
+	ArxivID    string
+	DOI        string
+	OpenAlexID string // 例如 W2741809807
+	S2ID       string // S2 paperId 或 CorpusId:N
+}
+
+// parsePaperRef 识别论文标识：arXiv ID（可带 arxiv: 前缀或为 arxiv.org 链接、10.48550 DOI）、
+// DOI（可带 doi: 前缀或为 doi.org 链接）、OpenAlex W-ID（可带 openalex: 前缀或为 openalex.org 链接）、
+// S2 paperId（可带 s2: 前缀）或 CorpusId:N
+func parsePaperRef(raw string) (paperRef, bool) {
+	raw = strings.TrimSpace(strings.TrimPrefix(raw, "/"))
+	lower := strings.ToLower(raw)
+	switch {
+	case raw == "":
+		return paperRef{}, false
+	case strings.HasPrefix(lower, "openalex:"), strings.Contains(lower, "openalex.org/"):
+		id := provider.NormalizeOpenAlexID(strings.TrimPrefix(lower, "openalex:"))
+		return paperRef{OpenAlexID: id}, openAlexWorkIDPattern.MatchString(id)
+	case strings.HasPrefix(lower, "s2:"):
+		id := raw[len("s2:"):]
+		return paperRef{S2ID: id}, s2PaperIDPattern.MatchString(id)
+	case strings.HasPrefix(lower, "corpusid:"):
+		id := raw[len("corpusid:"):]
+		_, err := strconv.Atoi(id)
+		return paperRef{S2ID: "CorpusId:" + id}, err == nil
+	}
+	if id := provider.ExtractArxivID(raw); id != "" {
+		return paperRef{ArxivID: id}, true
+	}
+	if arxivNewIDPattern.MatchString(raw) || arxivOldIDPattern.MatchString(raw) {
+		return paperRef{ArxivID: provider.ExtractArxivID("arxiv:" + raw)}, true
+	}
+	if doi := provider.NormalizeDOI(raw); strings.HasPrefix(doi, "10.") && strings.Contains(doi, "/") {
+		return paperRef{DOI: doi}, true
+	}
+	if openAlexWorkIDPattern.MatchString(raw) {
+		return paperRef{OpenAlexID: strings.ToUpper(raw)}, true
+	}
+	if s2PaperIDPattern.MatchString(raw) {
+		return paperRef{S2ID: lower}, true
+	}
+	return paperRef{}, false
+}
+
+// key 返回标识的缓存键
+func (r paperRef) key() string {
+	return cache.Key("paper", r.ArxivID, r.DOI, r.OpenAlexID, r.S2ID)
+}
+
+// fill 用查到的论文补全缺失的标识
+func (r *paperRef) fill(p model.Paper) {
+	if r.ArxivID == "" {
+		r.ArxivID = p.ArxivID
+		if r.ArxivID == "" {
+			r.ArxivID = provider.ExtractArxivID(p.DOI)
+		}
+	}
+	if doi := provider.NormalizeDOI(p.DOI); r.DOI == "" && doi != "" && provider.ExtractArxivID(doi) == "" {
+		r.DOI = doi
+	}
+	if r.OpenAlexID == "" && p.Source == "openalex" {
+		r.OpenAlexID = provider.NormalizeOpenAlexID(p.ID)
+	}
+	if r.S2ID == "" {
+		r.S2ID = p.S2ID
+	}
+}
+
+// openAlexLookupID 返回用于 OpenAlex 单篇查询的 ID，arXiv 论文按 10.48550 DOI 查找
+func (r paperRef) openAlexLookupID() string {
+	switch {
+	case r.OpenAlexID != "":
+		return r.OpenAlexID
+	case r.DOI != "":
+		return "doi:" + r.DOI
+	case r.ArxivID != "":
+		return "doi:10.48550/arxiv." + r.ArxivID
+	}
+	return ""
+}
+
+// s2LookupID 返回用于 S2 单篇查询的 ID
+func (r paperRef) s2LookupID() string {
+	switch {
+	case r.S2ID != "":
+		return r.S2ID
+	case r.ArxivID != "":
+		return "ARXIV:" + r.ArxivID
+	case r.DOI != "":
+		return "DOI:" + r.DOI
+	}
+	return ""
+}
+
+// paperRecord 是解析后的论文与参考文献，按请求的标识缓存
+type paperRecord struct {
+	Paper      model.Paper          `json:"paper"`
+	References []model.Paper        `json:"references"`
+	OpenAlexID string               `json:"openalex_id,omitempty"`
+	S2ID       string               `json:"s2_id,omitempty"`
+	Sources    []model.SourceStatus `json:"sources"`
+}
+
+// paperDetail 是 /papers/:id 的响应体
+type paperDetail struct {
+	Paper        model.Paper          `json:"paper"`
+	References   []model.Paper        `json:"references"`
+	Citing       []model.Paper        `json:"citing"`
+	CitingSource string               `json:"citing_source,omitempty"` // 被引列表的来源：openalex 或 s2
+	NextCursor   string               `json:"next_cursor,omitempty"`   // 被引列表下一页的游标，为空表示没有更多结果
+	Sources      []model.SourceStatus `json:"sources"`
+}
+
+// citingPage 是缓存的一页被引论文
+type citingPage struct {
+	Papers []model.Paper `json:"papers"`
+	Next   string        `json:"next,omitempty"`
+}
+
+// GetPaper 处理 /papers/:id?limit=&cursor=，id 为 arXiv ID、DOI、OpenAlex W-ID 或 S2 paperId（见 parsePaperRef）。
+// 依次在 OpenAlex 与 S2 中查找论文（用一方返回的 arXiv ID 或 DOI 补全另一方的查询），与本地库中的记录融合为一条，
+// 附上两边合并去重的参考文献，以及分页的被引论文（优先 OpenAlex，按发布日期从新到旧；不可用时改用 S2）
+func GetPaper(c *gin.Context) {
+	ref, ok := parsePaperRef(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid paper id, expected an arXiv ID, DOI, OpenAlex work ID or S2 paper ID"})
+		return
+	}
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultCitingLimit
+	}
+	limit = min(limit, maxCitingLimit)
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), requestDeadline)
+	defer cancel()
+
+	var rec paperRecord
+	fresh, ok := getCached(ref.key(), &rec)
+	cacheStatus := cache.StatusHit
+	if !ok || !fresh {
+		built, err := resolvePaper(ctx, ref)
+		switch {
+		case err == nil:
+			rec = *built
+			cacheStatus = cache.StatusMiss
+			// 部分数据源失败时不缓存，下次请求重新查询
+			if !anyFailed(rec.Sources) {
+				setCached(ref.key(), rec, paperCacheTTL)
+			}
+		case ok && !errors.Is(err, provider.ErrNotFound):
+			fmt.Printf("paper error, serving stale cache: %v\n", err)
+			cacheStatus = cache.StatusStale
+		case errors.Is(err, provider.ErrNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "paper not found"})
+			return
+		default:
+			c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	if responseCache != nil {
+		c.Header("X-Cache-Status", "paper="+string(cacheStatus))
+	}
+
+	cur, err := decodeCursor(c.Query("cursor"), cursorFingerprint("citing", rec.OpenAlexID, rec.S2ID, strconv.Itoa(limit)))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	detail := paperDetail{
+		Paper:      rec.Paper,
+		References: rec.References,
+		Citing:     []model.Paper{},
+		Sources:    append([]model.SourceStatus(nil), rec.Sources...),
+	}
+	var statuses []model.SourceStatus
+	detail.Citing, detail.CitingSource, detail.NextCursor, statuses = fetchCiting(ctx, &rec, cur, limit)
+	detail.Sources = append(detail.Sources, statuses...)
+	c.JSON(http.StatusOK, detail)
+}
+
+// resolvePaper 在 OpenAlex 与 S2 中查找论文并读取参考文献。两个数据源都没有找到时返回 ErrNotFound，
+// 都失败时返回最后一个错误
+func resolvePaper(ctx context.Context, ref paperRef) (*paperRecord, error) {
+	rec := &paperRecord{}
+	var found []model.Paper
+	var openAlexRefs []string
+	var lastErr error
+	openAlexDone, s2Done := false, false
+	// 只有 S2 ID 时先查 S2，再用其 arXiv ID 或 DOI 查 OpenAlex，反之亦然
+	for pass := 0; pass < 2; pass++ {
+		if id := ref.openAlexLookupID(); !openAlexDone && id != "" {
+			openAlexDone = true
+			start := time.Now()
+			p, refs, err := provider.FetchOpenAlexWork(ctx, id)
+			rec.Sources = append(rec.Sources, lookupStatus("openalex", start, err))
+			if err == nil {
+				found = append(found, p)
+				openAlexRefs = refs
+				ref.fill(p)
+			} else if !errors.Is(err, provider.ErrNotFound) {
+				fmt.Println("openalex paper error:", err)
+				lastErr = err
+			}
+		}
+		if id := ref.s2LookupID(); !s2Done && id != "" {
+			s2Done = true
+			start := time.Now()
+			p, err := provider.FetchS2Paper(ctx, id)
+			rec.Sources = append(rec.Sources, lookupStatus("s2", start, err))
+			if err == nil {
+				found = append(found, p)
+				ref.fill(p)
+			} else if !errors.Is(err, provider.ErrNotFound) {
+				fmt.Println("s2 paper error:", err)
+				lastErr = err
+			}
+		}
+	}
+	ingestPapers(found)
+
+	// 本地库中的记录可能带有 arXiv 版本历史、DBLP venue 等其他来源的字段
+	if paperStore != nil {
+		for _, id := range []string{ref.ArxivID, ref.DOI, openAlexURL(ref.OpenAlexID), ref.S2ID} {
+			if id == "" {
+				continue
+			}
+			if stored, ok, err := paperStore.Get(id); err == nil && ok {
+				found = append(found, stored.Paper)
+				break
+			}
+		}
+	}
+	if len(found) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, provider.ErrNotFound
+	}
+	rec.Paper = merge.Fuse(found)
+	rec.OpenAlexID, rec.S2ID = ref.OpenAlexID, ref.S2ID
+
+	// 参考文献：OpenAlex 的 referenced_works 与 S2 的 references 合并去重，按引用量排序
+	var references []model.Paper
+	if len(openAlexRefs) > 0 {
+		start := time.Now()
+		papers, err := provider.FetchOpenAlexWorksByID(ctx, openAlexRefs[:min(len(openAlexRefs), maxReferences)])
+		rec.Sources = append(rec.Sources, sourceStatus("openalex:references", start, len(papers), err))
+		references = append(references, papers...)
+	}
+	if rec.S2ID != "" {
+		start := time.Now()
+		papers, err := provider.FetchS2References(ctx, rec.S2ID, maxReferences)
+		rec.Sources = append(rec.Sources, sourceStatus("s2:references", start, len(papers), err))
+		references = append(references, papers...)
+	}
+	references = merge.Papers(references)
+	sort.SliceStable(references, func(i, j int) bool { return references[i].Citations > references[j].Citations })
+	rec.References = references
+	if rec.References == nil {
+		rec.References = []model.Paper{}
+	}
+	return rec, nil
+}
+
+// fetchCiting 读取游标位置的一页被引论文，返回论文、来源、下一页游标与执行状态。
+// 第一页优先使用 OpenAlex，失败时改用 S2；之后的页沿用游标中记录的来源
+func fetchCiting(ctx context.Context, rec *paperRecord, cur *searchCursor, limit int) ([]model.Paper, string, string, []model.SourceStatus) {
+	var sources []string
+	for name := range cur.Sources {
+		sources = append(sources, name)
+	}
+	if len(sources) == 0 {
+		if rec.OpenAlexID != "" {
+			sources = append(sources, "openalex")
+		}
+		if rec.S2ID != "" {
+			sources = append(sources, "s2")
+		}
+	}
+
+	var statuses []model.SourceStatus
+	for _, source := range sources {
+		pos := ""
+		if sc := cur.Sources[source]; sc != nil {
+			pos = sc.Pos
+		}
+		var page citingPage
+		key := cache.Key("citing", source, rec.OpenAlexID, rec.S2ID, pos, strconv.Itoa(limit))
+		start := time.Now()
+		fresh, ok := getCached(key, &page)
+		if !ok || !fresh {
+			var err error
+			switch source {
+			case "openalex":
+				page.Papers, page.Next, err = provider.FetchOpenAlexCiting(ctx, rec.OpenAlexID, limit, pos)
+			case "s2":
+				offset, _ := strconv.Atoi(pos)
+				page.Papers, page.Next, err = provider.FetchS2Citations(ctx, rec.S2ID, limit, offset)
+			default:
+				err = fmt.Errorf("unknown citing source %q", source)
+			}
+			if err != nil && !ok {
+				fmt.Printf("%s citing error: %v\n", source, err)
+				statuses = append(statuses, sourceStatus(source+":citing", start, 0, err))
+				continue
+			}
+			if err == nil {
+				setCached(key, page, citingCacheTTL)
+			}
+		}
+		statuses = append(statuses, sourceStatus(source+":citing", start, len(page.Papers), nil))
+
+		next := ""
+		if page.Next != "" {
+			cur.Sources = map[string]*sourceCursor{source: {Pos: page.Next}}
+			next = encodeCursor(cur)
+		}
+		if page.Papers == nil {
+			page.Papers = []model.Paper{}
+		}
+		return page.Papers, source, next, statuses
+	}
+	return []model.Paper{}, "", "", statuses
+}
+
+// lookupStatus 构造单篇查询的执行状态，未找到不算失败
+func lookupStatus(source string, start time.Time, err error) model.SourceStatus {
+	if errors.Is(err, provider.ErrNotFound) {
+		return sourceStatus(source, start, 0, nil)
+	}
+	count := 1
+	if err != nil {
+		count = 0
+	}
+	return sourceStatus(source, start, count, err)
+}
+
+// anyFailed 判断是否有数据源失败或超时
+func anyFailed(statuses []model.SourceStatus) bool {
+	for _, s := range statuses {
+		if s.Status != model.SourceStatusOK {
+			return true
+		}
+	}
+	return false
+}
+
+func openAlexURL(id string) string {
+	if id == "" {
+		return ""
+	}
+	return "https://openalex.org/" + id
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	"paper-scraper/internal/cache"
+)
+
+// 作者档案、论文详情等聚合结果的响应缓存，与数据源共用，可通过 SetResponseCache 设置
+var responseCache *cache.Cache
+
+// SetResponseCache 设置响应缓存，nil 表示不缓存
+func SetResponseCache(c *cache.Cache) {
+	responseCache = c
+}
+
+// getCached 读取缓存的 JSON 结果到 v，ok 表示存在可用的缓存，fresh 表示未过期
+func getCached(key string, v any) (fresh, ok bool) {
+	if responseCache == nil {
+		return false, false
+	}
+	value, fresh, ok := responseCache.Get(key)
+	if !ok || json.Unmarshal(value, v) != nil {
+		return false, false
+	}
+	return fresh, true
+}
+
+// setCached 以 JSON 缓存结果，未配置缓存时返回 false
+func setCached(key string, v any, ttl time.Duration) bool {
+	if responseCache == nil {
+		return false
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return false
+	}
+	responseCache.Set(key, raw, ttl)
+	return true
+}
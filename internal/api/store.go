@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+	"paper-scraper/internal/query"
+	"paper-scraper/internal/store"
+)
+
+// paperStore 为可选的本地论文库；为 nil 时所有请求直接访问上游
+var paperStore *store.Store
+
+// SetStore 设置处理器使用的本地论文库
+func SetStore(s *store.Store) {
+	paperStore = s
+}
+
+// ingestPapers 将数据源返回的论文写入本地论文库
+func ingestPapers(papers []model.Paper) {
+	if paperStore == nil || len(papers) == 0 {
+		return
+	}
+	if _, err := paperStore.Upsert(papers); err != nil {
+		fmt.Println("store upsert error:", err)
+	}
+}
+
+// searchStoreFallback 在数据源失败时从本地论文库中检索该来源此前保存的论文，
+// 没有检索到论文时返回 false，调用方不应标记为已退回本地结果
+func searchStoreFallback(source string, q provider.Query) ([]model.Paper, bool) {
+	if paperStore == nil {
+		return nil, false
+	}
+	f := store.Filter{
+		Sources:   []string{source},
+		StartDate: q.StartDate,
+		EndDate:   q.EndDate,
+		Sort:      q.Sort,
+		Limit:     q.Limit,
+		Offset:    q.Offset,
+	}
+	// 检索表达式中的短语、字段、否定与 OR 需按语法匹配；只有纯文本查询时按词匹配
+	if q.Expr != nil {
+		f.Match = func(p model.Paper) bool { return query.Match(q.Expr, p) }
+	} else {
+		f.Text = q.Text
+	}
+	papers, err := paperStore.Search(f)
+	if err != nil {
+		fmt.Println("store search error:", err)
+		return nil, false
+	}
+	return papers, len(papers) > 0
+}
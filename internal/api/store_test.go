@@ -0,0 +1,62 @@
+package api
+
+import (
+	"path/filepath"
+	"testing"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+	"paper-scraper/internal/query"
+	"paper-scraper/internal/store"
+)
+
+func TestSearchStoreFallback(t *testing.T) {
+	s, err := store.Open(filepath.Join(t.TempDir(), "papers.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if _, err := s.Upsert([]model.Paper{
+		{ID: "1", Source: "arxiv", ArxivID: "2401.00001", Title: "Energy-based models", Authors: []string{"Yann LeCun"}, PublishedAt: "2024-01-01"},
+		{ID: "2", Source: "arxiv", ArxivID: "2401.00002", Title: "A survey of energy-based models", Authors: []string{"Yann LeCun"}, PublishedAt: "2024-01-02"},
+		{ID: "3", Source: "arxiv", ArxivID: "2401.00003", Title: "Diffusion models", Authors: []string{"Ada Lovelace"}, PublishedAt: "2024-01-03"},
+		{ID: "4", Source: "dblp", DOI: "10.1000/4", Title: "Energy-based models", Authors: []string{"Yann LeCun"}, PublishedAt: "2024-01-04"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	SetStore(s)
+	defer SetStore(nil)
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`energy`, []string{"2401.00002", "2401.00001"}},
+		{`author:"yann lecun" -survey`, []string{"2401.00001"}},
+		{`"energy-based models" OR diffusion`, []string{"2401.00003", "2401.00002", "2401.00001"}},
+		{`title:transformer`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			expr, err := query.Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			q := provider.Query{Text: query.Text(expr), Expr: expr, Limit: 10, Sort: "published_desc"}
+			papers, ok := searchStoreFallback("arxiv", q)
+			var got []string
+			for _, p := range papers {
+				got = append(got, p.ArxivID)
+			}
+			if ok != (len(tt.want) > 0) || len(got) != len(tt.want) {
+				t.Fatalf("fallback = %v (ok=%v), want %v", got, ok, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("fallback = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"net/http"
+
+	"paper-scraper/internal/model"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sourceEvent 是 /search/stream 中单个数据源返回时推送的事件
+type sourceEvent struct {
+	Source string             `json:"source"`
+	Status model.SourceStatus `json:"status"`
+	Items  []model.Paper      `json:"items"`
+}
+
+// StreamSearch 处理 /search/stream，参数与 /search 相同，以 Server-Sent Events 推送结果：
+// 每个数据源完成时推送 source 事件（该来源通过过滤的论文与执行状态），
+// 全部完成后推送 done 事件，内容为去重、补全、过滤与排序后的完整响应（与 /search 的响应体相同）。
+// 前端可先展示先返回的数据源，不必等待最慢的数据源
+func StreamSearch(c *gin.Context) {
+	req, status, err := parseSearchRequest(c)
+	if err != nil {
+		c.JSON(status, gin.H{"error": err.Error(), "sources": req.unknownStatuses()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no") // 关闭 nginx 等反向代理的缓冲
+	c.Status(http.StatusOK)
+
+	ctx := c.Request.Context()
+	papers, statuses, nextCursor := req.fetch(ctx, func(status model.SourceStatus, papers []model.Paper) {
+		items := []model.Paper{}
+		for _, p := range papers {
+			if req.keep(p) {
+				if len(p.Sources) == 0 {
+					p.Sources = []string{p.Source}
+				}
+				items = append(items, p)
+			}
+		}
+		c.SSEvent("source", sourceEvent{Source: status.Source, Status: status, Items: items})
+		c.Writer.Flush()
+	})
+
+	// 客户端已断开时不再做后续处理
+	if ctx.Err() != nil {
+		return
+	}
+	resp, _ := req.respond(ctx, papers, statuses, nextCursor)
+	c.SSEvent("done", resp)
+	c.Writer.Flush()
+}
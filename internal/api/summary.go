@@ -0,0 +1,225 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"paper-scraper/internal/analysis"
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/store"
+	"paper-scraper/internal/summary"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxHistoryDays 是 /daily-summary/history 一次可查询的最大天数
+	maxHistoryDays = 366
+	// maxBackfillDays 是一次请求最多补算的天数，其余缺失日期留待下次请求
+	maxBackfillDays = 7
+)
+
+// backgroundTTL 内复用已构建的主题历史
+const backgroundTTL = time.Hour
+
+type cachedHistory struct {
+	history *analysis.TopicHistory
+	built   time.Time
+}
+
+// historyCache 按“范围/起止日期”缓存主题历史，供每日摘要与 /trends 共用
+var historyCache = struct {
+	sync.Mutex
+	entries map[string]cachedHistory
+}{entries: make(map[string]cachedHistory)}
+
+// backgroundHistory 返回本地论文库中该范围在窗口开始前 30 天内论文的每日主题统计，
+// 既作为主题提取的背景语料，也作为趋势检测的基线。背景随日期滚动，没有本地库时返回 nil
+func backgroundHistory(profile *summary.Profile, startDate string) *analysis.TopicHistory {
+	if paperStore == nil {
+		return nil
+	}
+	start, err := time.Parse("2006-01-02", startDate)
+	if err != nil {
+		return nil
+	}
+	history, err := cachedTopicHistory(profile, start.AddDate(0, 0, -analysis.TrendBaselineDays), start.AddDate(0, 0, -1))
+	if err != nil {
+		fmt.Println("background history error:", err)
+		return nil
+	}
+	return history
+}
+
+// cachedTopicHistory 返回该范围在 [from, to] 内的主题历史，backgroundTTL 内复用已构建的结果
+func cachedTopicHistory(profile *summary.Profile, from, to time.Time) (*analysis.TopicHistory, error) {
+	key := profile.Name + "/" + from.Format("2006-01-02") + "/" + to.Format("2006-01-02")
+	historyCache.Lock()
+	defer historyCache.Unlock()
+	if e, ok := historyCache.entries[key]; ok && time.Since(e.built) < backgroundTTL {
+		return e.history, nil
+	}
+
+	history, err := loadTopicHistory(profile, from, to)
+	if err != nil {
+		return nil, err
+	}
+	// 过期的条目在写入时一并清理
+	for k, e := range historyCache.entries {
+		if time.Since(e.built) >= backgroundTTL {
+			delete(historyCache.entries, k)
+		}
+	}
+	historyCache.entries[key] = cachedHistory{history: history, built: time.Now()}
+	return history, nil
+}
+
+// loadTopicHistory 统计本地论文库中该范围在 [from, to] 内发布的论文的每日主题分布。
+// 遍历时逐篇累计，不加载论文本身，因此范围内的论文全部计入，不受论文数限制
+func loadTopicHistory(profile *summary.Profile, from, to time.Time) (*analysis.TopicHistory, error) {
+	b := analysis.NewTopicHistoryBuilder()
+	err := paperStore.Scan(store.Filter{
+		Sources:   profile.Sources,
+		StartDate: from.Format("2006-01-02"),
+		EndDate:   to.Format("2006-01-02"),
+		Match:     profile.Matches,
+	}, func(p model.Paper) error {
+		b.Add(p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return b.Build(), nil
+}
+
+// saveSummary 将每日摘要按范围与日期写入本地论文库
+func saveSummary(daily analysis.DailySummary) {
+	if paperStore == nil {
+		return
+	}
+	data, err := json.Marshal(daily)
+	if err != nil {
+		fmt.Println("summary encode error:", err)
+		return
+	}
+	if err := paperStore.PutSummary(daily.Profile, daily.Date, data); err != nil {
+		fmt.Println("summary save error:", err)
+	}
+}
+
+// loadSummary 读取已保存的每日摘要
+func loadSummary(profile, date string) (analysis.DailySummary, bool) {
+	var daily analysis.DailySummary
+	if paperStore == nil {
+		return daily, false
+	}
+	data, ok, err := paperStore.GetSummary(profile, date)
+	if err != nil {
+		fmt.Println("summary load error:", err)
+		return daily, false
+	}
+	if !ok {
+		return daily, false
+	}
+	if err := json.Unmarshal(data, &daily); err != nil {
+		fmt.Println("summary decode error:", err)
+		return daily, false
+	}
+	return daily, true
+}
+
+// summaryHistory 是 /daily-summary/history 的响应体
+type summaryHistory struct {
+	Profile   string                  `json:"profile"`
+	From      string                  `json:"from"`
+	To        string                  `json:"to"`
+	Summaries []analysis.DailySummary `json:"summaries"`
+	Missing   []string                `json:"missing,omitempty"` // 范围内尚未保存摘要的日期
+}
+
+// GetSummaryHistory 处理 /daily-summary/history?from=&to=&profile=，按日期升序返回已保存的每日摘要。
+// from 默认为 to 之前 6 天，to 默认为今天；backfill=true 时为缺失的日期拉取论文补算（每次最多 7 天，从最近的日期开始）
+func GetSummaryHistory(c *gin.Context) {
+	profile, ok := summaryConfig.Profile(c.Query("profile"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown profile", "profiles": summaryConfig.Names()})
+		return
+	}
+	if paperStore == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "summary archive requires a local store"})
+		return
+	}
+
+	today := time.Now().Format("2006-01-02")
+	to := c.DefaultQuery("to", today)
+	toT, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to, expected YYYY-MM-DD"})
+		return
+	}
+	from := c.DefaultQuery("from", toT.AddDate(0, 0, -6).Format("2006-01-02"))
+	fromT, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from, expected YYYY-MM-DD"})
+		return
+	}
+	if fromT.After(toT) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from is after to"})
+		return
+	}
+	if toT.Sub(fromT) >= maxHistoryDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("range exceeds %d days", maxHistoryDays)})
+		return
+	}
+
+	saved := make(map[string]analysis.DailySummary)
+	err = paperStore.ForEachSummary(profile.Name, from, to, func(date string, data []byte) error {
+		var daily analysis.DailySummary
+		if err := json.Unmarshal(data, &daily); err != nil {
+			return fmt.Errorf("decode summary %s: %w", date, err)
+		}
+		saved[date] = daily
+		return nil
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// 从最近的日期往前补算缺失的摘要，未来的日期不补算
+	if c.Query("backfill") == "true" {
+		ctx := c.Request.Context()
+		filled := 0
+		for d := toT; !d.Before(fromT) && filled < maxBackfillDays && ctx.Err() == nil; d = d.AddDate(0, 0, -1) {
+			date := d.Format("2006-01-02")
+			if _, ok := saved[date]; ok || date > today {
+				continue
+			}
+			filled++
+			daily := generateDailySummary(ctx, profile, date, nil)
+			if ctx.Err() != nil || allSourcesFailed(daily.Sources) {
+				continue
+			}
+			saveSummary(daily)
+			saved[date] = daily
+		}
+		if ctx.Err() != nil {
+			return
+		}
+	}
+
+	resp := summaryHistory{Profile: profile.Name, From: from, To: to, Summaries: []analysis.DailySummary{}}
+	for d := fromT; !d.After(toT); d = d.AddDate(0, 0, 1) {
+		date := d.Format("2006-01-02")
+		if daily, ok := saved[date]; ok {
+			resp.Summaries = append(resp.Summaries, daily)
+		} else {
+			resp.Missing = append(resp.Missing, date)
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"paper-scraper/internal/analysis"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxTrendDays 是 /trends 一次可查询的最大天数
+const maxTrendDays = 366
+
+// topicSeries 是 /trends?topic= 的响应体
+type topicSeries struct {
+	Profile string                `json:"profile"`
+	Topic   string                `json:"topic"`
+	From    string                `json:"from"`
+	To      string                `json:"to"`
+	Points  []analysis.TrendPoint `json:"points"`
+}
+
+// GetTrends 处理 /trends?topic=&from=&to=&profile=，基于本地论文库中的历史论文：
+//   - 指定 topic 时返回该主题在 [from, to]（默认最近 30 天）内的每日论文数、占比与相对此前 30 天基线的 z 分数，
+//     z 分数超过阈值的日期标记为突发；
+//   - 未指定 topic 时返回近期窗口 [from, to]（默认为该范围的摘要窗口）相对其前 30 天的上升与下降主题及增长率
+func GetTrends(c *gin.Context) {
+	profile, ok := summaryConfig.Profile(c.Query("profile"))
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unknown profile", "profiles": summaryConfig.Names()})
+		return
+	}
+	if paperStore == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "trends require a local store"})
+		return
+	}
+
+	topic := c.Query("topic")
+	to := c.DefaultQuery("to", time.Now().Format("2006-01-02"))
+	toT, err := time.Parse("2006-01-02", to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to, expected YYYY-MM-DD"})
+		return
+	}
+	defaultFrom, _ := profile.Window(toT)
+	if topic != "" {
+		defaultFrom = toT.AddDate(0, 0, -(analysis.TrendBaselineDays - 1)).Format("2006-01-02")
+	}
+	from := c.DefaultQuery("from", defaultFrom)
+	fromT, err := time.Parse("2006-01-02", from)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from, expected YYYY-MM-DD"})
+		return
+	}
+	if fromT.After(toT) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from is after to"})
+		return
+	}
+	if toT.Sub(fromT) >= maxTrendDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("range exceeds %d days", maxTrendDays)})
+		return
+	}
+
+	// 基线需要 from 之前 30 天的数据；结果与每日摘要共用缓存，1 小时内的新论文可能尚未计入
+	history, err := cachedTopicHistory(profile, fromT.AddDate(0, 0, -analysis.TrendBaselineDays), toT)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if topic != "" {
+		c.JSON(http.StatusOK, topicSeries{
+			Profile: profile.Name,
+			Topic:   topic,
+			From:    from,
+			To:      to,
+			Points:  history.Series(topic, from, to),
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"profile": profile.Name,
+		"trends":  analysis.DetectTrends(history, history, from, to),
+	})
+}
@@ -0,0 +1,320 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Status 描述一次读取的缓存命中情况
+type Status string
+
+const (
+	StatusHit    Status = "hit"    // 命中且未过期
+	StatusMiss   Status = "miss"   // 未命中，访问了上游
+	StatusStale  Status = "stale"  // 上游失败，返回了过期的缓存
+	StatusBypass Status = "bypass" // 未经过缓存
+)
+
+type entry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// diskEntry 是磁盘层的文件格式
+type diskEntry struct {
+	Key     string    `json:"key"`
+	Value   []byte    `json:"value"`
+	Expires time.Time `json:"expires"`
+}
+
+const (
+	// 磁盘层默认的保留时长与总大小上限，见 SetDiskLimits
+	defaultDiskMaxAge   = 7 * 24 * time.Hour
+	defaultDiskMaxBytes = 512 << 20
+	// pruneInterval 是两次清理磁盘层之间的最短间隔
+	pruneInterval = 10 * time.Minute
+)
+
+// Cache 是两级缓存：内存 LRU 层与可选的磁盘层。
+// 过期条目不会立即删除，以便上游失败时仍可返回旧数据；
+// 磁盘层在写入时定期清理，删除超过保留时长的文件，并在超过总大小上限时从最早写入的开始删除
+type Cache struct {
+	mu       sync.Mutex
+	capacity int
+	ll       *list.List
+	items    map[string]*list.Element
+	dir      string // 磁盘层目录，为空表示不启用
+
+	maxAge    time.Duration // 磁盘条目自写入起的保留时长
+	maxBytes  int64         // 磁盘层的总大小上限
+	lastPrune time.Time
+	pruning   bool
+}
+
+// New 创建容量为 capacity 条的缓存；dir 非空时启用磁盘层
+func New(capacity int, dir string) (*Cache, error) {
+	if capacity <= 0 {
+		capacity = 512
+	}
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	return &Cache{
+		capacity: capacity,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		dir:      dir,
+		maxAge:   defaultDiskMaxAge,
+		maxBytes: defaultDiskMaxBytes,
+	}, nil
+}
+
+// SetDiskLimits 设置磁盘层的保留时长与总大小上限（字节），非正数表示使用默认值（7 天、512 MB）
+func (c *Cache) SetDiskLimits(maxAge time.Duration, maxBytes int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maxAge, c.maxBytes = defaultDiskMaxAge, int64(defaultDiskMaxBytes)
+	if maxAge > 0 {
+		c.maxAge = maxAge
+	}
+	if maxBytes > 0 {
+		c.maxBytes = maxBytes
+	}
+}
+
+// Get 读取 key 对应的值，fresh 表示是否尚未过期
+func (c *Cache) Get(key string) (value []byte, fresh bool, ok bool) {
+	c.mu.Lock()
+	if el, found := c.items[key]; found {
+		c.ll.MoveToFront(el)
+		e := el.Value.(*entry)
+		c.mu.Unlock()
+		return e.value, time.Now().Before(e.expires), true
+	}
+	c.mu.Unlock()
+
+	if c.dir == "" {
+		return nil, false, false
+	}
+	raw, err := os.ReadFile(c.path(key))
+	if err != nil {
+		return nil, false, false
+	}
+	var de diskEntry
+	if err := json.Unmarshal(raw, &de); err != nil || de.Key != key {
+		return nil, false, false
+	}
+	// 回填内存层
+	c.setMemory(key, de.Value, de.Expires)
+	return de.Value, time.Now().Before(de.Expires), true
+}
+
+// Set 写入 key，ttl 后过期
+func (c *Cache) Set(key string, value []byte, ttl time.Duration) {
+	expires := time.Now().Add(ttl)
+	c.setMemory(key, value, expires)
+
+	if c.dir == "" {
+		return
+	}
+	raw, err := json.Marshal(diskEntry{Key: key, Value: value, Expires: expires})
+	if err != nil {
+		return
+	}
+	// 先写独立的临时文件再重命名，避免并发读到半个文件，同一 key 的并发写入也不会交错
+	tmp, err := os.CreateTemp(c.dir, "*.tmp")
+	if err != nil {
+		fmt.Println("cache write error:", err)
+		return
+	}
+	_, err = tmp.Write(raw)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path(key))
+	}
+	if err != nil {
+		fmt.Println("cache write error:", err)
+		os.Remove(tmp.Name())
+		return
+	}
+
+	c.mu.Lock()
+	due := !c.pruning && time.Since(c.lastPrune) >= pruneInterval
+	if due {
+		c.pruning = true
+	}
+	c.mu.Unlock()
+	if due {
+		go func() {
+			if _, err := c.Prune(); err != nil {
+				fmt.Println("cache prune error:", err)
+			}
+			c.mu.Lock()
+			c.pruning, c.lastPrune = false, time.Now()
+			c.mu.Unlock()
+		}()
+	}
+}
+
+// Prune 清理磁盘层：删除写入时间超过保留时长的文件（包括残留的临时文件），
+// 总大小仍超过上限时从最早写入的开始删除。返回删除的文件数
+func (c *Cache) Prune() (int, error) {
+	if c.dir == "" {
+		return 0, nil
+	}
+	c.mu.Lock()
+	maxAge, maxBytes := c.maxAge, c.maxBytes
+	c.mu.Unlock()
+
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return 0, err
+	}
+	type diskFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+	var files []diskFile
+	var total int64
+	removed := 0
+	cutoff := time.Now().Add(-maxAge)
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !(strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".tmp")) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		path := filepath.Join(c.dir, name)
+		if info.ModTime().Before(cutoff) {
+			if os.Remove(path) == nil {
+				removed++
+			}
+			continue
+		}
+		if strings.HasSuffix(name, ".tmp") {
+			continue // 可能正在写入，只按保留时长清理
+		}
+		files = append(files, diskFile{path: path, size: info.Size(), modTime: info.ModTime()})
+		total += info.Size()
+	}
+
+	sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+	for _, f := range files {
+		if total <= maxBytes {
+			break
+		}
+		if os.Remove(f.path) == nil {
+			removed++
+			total -= f.size
+		}
+	}
+	return removed, nil
+}
+
+func (c *Cache) setMemory(key string, value []byte, expires time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, found := c.items[key]; found {
+		c.ll.MoveToFront(el)
+		e := el.Value.(*entry)
+		e.value, e.expires = value, expires
+		return
+	}
+	c.items[key] = c.ll.PushFront(&entry{key: key, value: value, expires: expires})
+	for c.ll.Len() > c.capacity {
+		oldest := c.ll.Back()
+		c.ll.Remove(oldest)
+		delete(c.items, oldest.Value.(*entry).key)
+	}
+}
+
+// Len 返回内存层中的条目数
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ll.Len()
+}
+
+func (c *Cache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+// Key 由各部分拼接出缓存键
+func Key(parts ...string) string {
+	return strings.Join(parts, "\x1f")
+}
+
+// --- 通过 context 回传命中情况 ---
+
+type recorderKey struct{}
+
+// Recorder 记录一次调用链中的缓存命中情况
+type Recorder struct {
+	mu     sync.Mutex
+	status Status
+}
+
+func (r *Recorder) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.status == "" {
+		return StatusBypass
+	}
+	return r.status
+}
+
+// WithRecorder 返回携带 Recorder 的 context，供调用方在调用结束后读取缓存状态
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	r := &Recorder{}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// Record 将缓存状态写入 context 中的 Recorder（如有）
+func Record(ctx context.Context, status Status) {
+	if r, ok := ctx.Value(recorderKey{}).(*Recorder); ok {
+		r.mu.Lock()
+		r.status = status
+		r.mu.Unlock()
+	}
+}
+
+// ParseTTLs 解析形如 "arxiv=30m,openalex=1h" 的每数据源 TTL 配置
+func ParseTTLs(spec string) (map[string]time.Duration, error) {
+	ttls := make(map[string]time.Duration)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid ttl %q", item)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid ttl %q: %w", item, err)
+		}
+		ttls[strings.ToLower(strings.TrimSpace(name))] = d
+	}
+	return ttls, nil
+}
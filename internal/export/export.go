@@ -0,0 +1,402 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+)
+
+// 支持的导出格式
+const (
+	FormatBibTeX  = "bibtex"
+	FormatRIS     = "ris"
+	FormatCSLJSON = "csljson"
+	FormatCSV     = "csv"
+)
+
+// Format 描述一种导出格式的 MIME 类型与文件扩展名
+type Format struct {
+	ContentType string
+	Extension   string
+}
+
+var Formats = map[string]Format{
+	FormatBibTeX:  {ContentType: "application/x-bibtex; charset=utf-8", Extension: "bib"},
+	FormatRIS:     {ContentType: "application/x-research-info-systems; charset=utf-8", Extension: "ris"},
+	FormatCSLJSON: {ContentType: "application/vnd.citationstyles.csl+json; charset=utf-8", Extension: "json"},
+	FormatCSV:     {ContentType: "text/csv; charset=utf-8", Extension: "csv"},
+}
+
+// Write 将论文按指定格式写入 w
+func Write(w io.Writer, format string, papers []model.Paper) error {
+	keys := CitationKeys(papers)
+	switch format {
+	case FormatBibTeX:
+		return writeBibTeX(w, papers, keys)
+	case FormatRIS:
+		return writeRIS(w, papers)
+	case FormatCSLJSON:
+		return writeCSLJSON(w, papers, keys)
+	case FormatCSV:
+		return writeCSV(w, papers, keys)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// 生成引用键时跳过的标题词
+var keyStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "on": true, "of": true, "for": true,
+	"in": true, "to": true, "and": true, "with": true, "towards": true, "toward": true,
+}
+
+// CitationKeys 为每篇论文生成稳定的引用键：第一作者姓氏 + 年份 + 标题首个实词，
+// 例如 "vaswani2017attention"；同一批导出中重复的键追加 a、b、c… 后缀
+func CitationKeys(papers []model.Paper) []string {
+	keys := make([]string, len(papers))
+	counts := make(map[string]int)
+	for i, p := range papers {
+		keys[i] = citationKey(p)
+		counts[keys[i]]++
+	}
+	seen := make(map[string]int)
+	for i, key := range keys {
+		if counts[key] > 1 {
+			keys[i] = key + string(rune('a'+seen[key]%26))
+			seen[key]++
+		}
+	}
+	return keys
+}
+
+func citationKey(p model.Paper) string {
+	author := "anon"
+	if len(p.Authors) > 0 {
+		if family, _ := splitName(p.Authors[0]); family != "" {
+			author = asciiWord(family)
+		}
+	}
+	year := ""
+	if y := paperYear(p); y != 0 {
+		year = strconv.Itoa(y)
+	}
+	word := ""
+	for _, w := range strings.Fields(p.Title) {
+		w = asciiWord(w)
+		if w != "" && !keyStopWords[w] {
+			word = w
+			break
+		}
+	}
+	if author == "" {
+		author = "anon"
+	}
+	return author + year + word
+}
+
+// asciiWord 去除重音并仅保留小写字母与数字，例如 "Müller," -> "muller"
+func asciiWord(s string) string {
+	var b strings.Builder
+	for _, r := range norm.NFD.String(s) {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			b.WriteRune(unicode.ToLower(r))
+		}
+	}
+	return b.String()
+}
+
+// splitName 将作者名拆为姓与名，兼容 "Last, First" 与 "First Middle Last"
+func splitName(name string) (family, given string) {
+	name = strings.TrimSpace(name)
+	if before, after, ok := strings.Cut(name, ","); ok {
+		return strings.TrimSpace(before), strings.TrimSpace(after)
+	}
+	fields := strings.Fields(name)
+	if len(fields) == 0 {
+		return "", ""
+	}
+	return fields[len(fields)-1], strings.Join(fields[:len(fields)-1], " ")
+}
+
+func paperYear(p model.Paper) int {
+	if p.Year != nil && *p.Year != 0 {
+		return *p.Year
+	}
+	if t := provider.ParseDate(p.PublishedAt); !t.IsZero() {
+		return t.Year()
+	}
+	return 0
+}
+
+// entryKind 判断论文类型：期刊论文、会议论文或预印本/其他
+type entryKind int
+
+const (
+	kindMisc entryKind = iota
+	kindJournal
+	kindConference
+)
+
+func kindOf(p model.Paper) entryKind {
+	// 没有 journal_ref 的 arXiv 记录以作者注释作为 venue，按预印本导出
+	if provider.IsPlaceholderVenue(p.Venue) || provider.IsCommentVenue(p) {
+		return kindMisc
+	}
+	venue := strings.ToLower(strings.TrimSpace(p.Venue))
+	for _, c := range p.Categories {
+		switch c {
+		case "Journal Articles":
+			return kindJournal
+		case "Conference and Workshop Papers":
+			return kindConference
+		}
+	}
+	for _, hint := range []string{"journal", "transactions", "letters", "review", "magazine"} {
+		if strings.Contains(venue, hint) {
+			return kindJournal
+		}
+	}
+	if journalAbbrevs[venue] {
+		return kindJournal
+	}
+	return kindConference
+}
+
+// CCF 目录中以缩写形式出现的期刊
+var journalAbbrevs = map[string]bool{
+	"tpami": true, "pami": true, "ijcv": true, "tip": true, "tvcg": true, "tog": true,
+	"tochi": true, "tmm": true, "tcsvt": true, "cviu": true, "pr": true,
+}
+
+// --- BibTeX ---
+
+var bibEscaper = strings.NewReplacer(
+	`\`, `\textbackslash{}`, "{", `\{`, "}", `\}`, "&", `\&`, "%", `\%`,
+	"$", `\$`, "#", `\#`, "_", `\_`, "~", `\textasciitilde{}`, "^", `\textasciicircum{}`,
+)
+
+func writeBibTeX(w io.Writer, papers []model.Paper, keys []string) error {
+	for i, p := range papers {
+		var fields [][2]string
+		add := func(name, value string) {
+			if value != "" {
+				fields = append(fields, [2]string{name, value})
+			}
+		}
+
+		entryType := "misc"
+		switch kindOf(p) {
+		case kindJournal:
+			entryType = "article"
+			add("journal", bibEscaper.Replace(p.Venue))
+		case kindConference:
+			entryType = "inproceedings"
+			add("booktitle", bibEscaper.Replace(p.Venue))
+		}
+
+		// 标题外层再加一对花括号以保留大小写
+		add("title", "{"+bibEscaper.Replace(p.Title)+"}")
+		add("author", bibEscaper.Replace(strings.Join(p.Authors, " and ")))
+		if y := paperYear(p); y != 0 {
+			add("year", strconv.Itoa(y))
+		}
+		add("doi", p.DOI)
+		add("url", p.URL)
+		if p.ArxivID != "" {
+			add("eprint", p.ArxivID)
+			add("archiveprefix", "arXiv")
+			if len(p.Categories) > 0 && strings.HasPrefix(p.Categories[0], "cs.") {
+				add("primaryclass", p.Categories[0])
+			}
+		}
+		add("abstract", bibEscaper.Replace(p.Abstract))
+
+		if _, err := fmt.Fprintf(w, "@%s{%s,\n", entryType, keys[i]); err != nil {
+			return err
+		}
+		for j, f := range fields {
+			sep := ","
+			if j == len(fields)-1 {
+				sep = ""
+			}
+			if _, err := fmt.Fprintf(w, "  %s = {%s}%s\n", f[0], f[1], sep); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "}\n\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- RIS ---
+
+func writeRIS(w io.Writer, papers []model.Paper) error {
+	for _, p := range papers {
+		var lines [][2]string
+		add := func(tag, value string) {
+			if value != "" {
+				lines = append(lines, [2]string{tag, strings.ReplaceAll(value, "\n", " ")})
+			}
+		}
+
+		switch kindOf(p) {
+		case kindJournal:
+			add("TY", "JOUR")
+			add("JO", p.Venue)
+		case kindConference:
+			add("TY", "CPAPER")
+			add("T2", p.Venue)
+		default:
+			if p.ArxivID != "" {
+				add("TY", "UNPB")
+			} else {
+				add("TY", "GEN")
+			}
+		}
+		add("TI", p.Title)
+		for _, a := range p.Authors {
+			family, given := splitName(a)
+			if given != "" {
+				add("AU", family+", "+given)
+			} else {
+				add("AU", family)
+			}
+		}
+		if y := paperYear(p); y != 0 {
+			add("PY", strconv.Itoa(y))
+		}
+		if t := provider.ParseDate(p.PublishedAt); !t.IsZero() {
+			add("DA", t.Format("2006/01/02"))
+		}
+		add("AB", p.Abstract)
+		add("DO", p.DOI)
+		add("UR", p.URL)
+		if p.ArxivID != "" {
+			add("N1", "arXiv:"+p.ArxivID)
+		}
+		for _, c := range p.Categories {
+			add("KW", c)
+		}
+
+		for _, l := range lines {
+			if _, err := fmt.Fprintf(w, "%s  - %s\n", l[0], l[1]); err != nil {
+				return err
+			}
+		}
+		if _, err := io.WriteString(w, "ER  - \n\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// --- CSL-JSON ---
+
+type cslName struct {
+	Family string `json:"family,omitempty"`
+	Given  string `json:"given,omitempty"`
+}
+
+type cslDate struct {
+	DateParts [][]int `json:"date-parts"`
+}
+
+type cslItem struct {
+	ID             string    `json:"id"`
+	Type           string    `json:"type"`
+	Title          string    `json:"title"`
+	Author         []cslName `json:"author,omitempty"`
+	Issued         *cslDate  `json:"issued,omitempty"`
+	ContainerTitle string    `json:"container-title,omitempty"`
+	Publisher      string    `json:"publisher,omitempty"`
+	Number         string    `json:"number,omitempty"`
+	DOI            string    `json:"DOI,omitempty"`
+	URL            string    `json:"URL,omitempty"`
+	Abstract       string    `json:"abstract,omitempty"`
+}
+
+func writeCSLJSON(w io.Writer, papers []model.Paper, keys []string) error {
+	items := make([]cslItem, 0, len(papers))
+	for i, p := range papers {
+		item := cslItem{
+			ID:       keys[i],
+			Title:    p.Title,
+			DOI:      p.DOI,
+			URL:      p.URL,
+			Abstract: p.Abstract,
+		}
+		switch kindOf(p) {
+		case kindJournal:
+			item.Type = "article-journal"
+			item.ContainerTitle = p.Venue
+		case kindConference:
+			item.Type = "paper-conference"
+			item.ContainerTitle = p.Venue
+		default:
+			// CSL 中预印本使用 "article" 类型
+			item.Type = "article"
+			if p.ArxivID != "" {
+				item.Publisher = "arXiv"
+				item.Number = "arXiv:" + p.ArxivID
+			}
+		}
+		for _, a := range p.Authors {
+			family, given := splitName(a)
+			item.Author = append(item.Author, cslName{Family: family, Given: given})
+		}
+		if t := provider.ParseDate(p.PublishedAt); !t.IsZero() {
+			item.Issued = &cslDate{DateParts: [][]int{{t.Year(), int(t.Month()), t.Day()}}}
+		} else if y := paperYear(p); y != 0 {
+			item.Issued = &cslDate{DateParts: [][]int{{y}}}
+		}
+		items = append(items, item)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(items)
+}
+
+// --- CSV ---
+
+func writeCSV(w io.Writer, papers []model.Paper, keys []string) error {
+	cw := csv.NewWriter(w)
+	header := []string{"key", "title", "authors", "year", "venue", "ccf_class", "doi", "arxiv_id", "url", "published_at", "citations", "sources"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i, p := range papers {
+		year := ""
+		if y := paperYear(p); y != 0 {
+			year = strconv.Itoa(y)
+		}
+		sources := p.Sources
+		if len(sources) == 0 {
+			sources = []string{p.Source}
+		}
+		// 与其他格式一致，不把 arXiv 作者注释当作 venue 导出
+		venue := p.Venue
+		if provider.IsCommentVenue(p) {
+			venue = ""
+		}
+		record := []string{
+			keys[i], p.Title, strings.Join(p.Authors, "; "), year, venue, p.CCFClass,
+			p.DOI, p.ArxivID, p.URL, p.PublishedAt, strconv.Itoa(p.Citations), strings.Join(sources, ";"),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
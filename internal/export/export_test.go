@@ -0,0 +1,166 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"paper-scraper/internal/model"
+)
+
+func TestKindOf(t *testing.T) {
+	tests := []struct {
+		name  string
+		paper model.Paper
+		want  entryKind
+	}{
+		{"conference", model.Paper{Venue: "CVPR"}, kindConference},
+		{"journal hint", model.Paper{Venue: "IEEE Transactions on Image Processing"}, kindJournal},
+		{"journal abbreviation", model.Paper{Venue: "TPAMI"}, kindJournal},
+		{"dblp journal category", model.Paper{Venue: "Neurocomputing", Categories: []string{"Journal Articles"}}, kindJournal},
+		{"placeholder venue", model.Paper{Venue: "arXiv"}, kindMisc},
+		{"empty venue", model.Paper{}, kindMisc},
+		{"arxiv comment", model.Paper{Venue: "12 pages, 4 figures", Comment: "12 pages, 4 figures"}, kindMisc},
+		{"comment replaced by venue", model.Paper{Venue: "ICCV", Comment: "12 pages, 4 figures"}, kindConference},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kindOf(tt.paper); got != tt.want {
+				t.Errorf("kindOf = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCitationKeys(t *testing.T) {
+	year := 2017
+	papers := []model.Paper{
+		{Title: "Attention Is All You Need", Authors: []string{"Ashish Vaswani"}, Year: &year},
+		{Title: "The Müller Method", Authors: []string{"Müller, Jörg"}, PublishedAt: "2020-05-01"},
+		{Title: "On Graphs", Authors: []string{"Ada Lovelace"}, PublishedAt: "2021-01-01"},
+		{Title: "On Graphs", Authors: []string{"Ada Lovelace"}, PublishedAt: "2021-02-01"},
+		{Title: "Untitled"},
+	}
+	want := []string{"vaswani2017attention", "muller2020muller", "lovelace2021graphsa", "lovelace2021graphsb", "anonuntitled"}
+	if got := CitationKeys(papers); !reflect.DeepEqual(got, want) {
+		t.Errorf("CitationKeys = %q, want %q", got, want)
+	}
+}
+
+// samplePapers 是一篇会议论文与一篇 arXiv 预印本
+func samplePapers() []model.Paper {
+	return []model.Paper{
+		{
+			Title: "Deep Residual Learning & More", Authors: []string{"Kaiming He", "Jian Sun"},
+			Venue: "CVPR", CCFClass: "A", DOI: "10.1109/cvpr.2016.90", PublishedAt: "2016-06-27",
+			Source: "dblp", Citations: 100,
+		},
+		{
+			Title: "Some preprint", Authors: []string{"Ada Lovelace"}, ArxivID: "2401.00001",
+			Venue: "10 pages", Comment: "10 pages", Categories: []string{"cs.LG"},
+			PublishedAt: "2024-01-02", URL: "http://arxiv.org/abs/2401.00001v1", Sources: []string{"arxiv", "s2"},
+		},
+	}
+}
+
+func TestWriteBibTeX(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatBibTeX, samplePapers()); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"@inproceedings{he2016deep,\n",
+		"  booktitle = {CVPR},\n",
+		`  title = {{Deep Residual Learning \& More}},`,
+		"  author = {Kaiming He and Jian Sun},\n",
+		"@misc{lovelace2024some,\n",
+		"  eprint = {2401.00001},\n",
+		"  archiveprefix = {arXiv},\n",
+		"  primaryclass = {cs.LG}\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("BibTeX output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "10 pages") {
+		t.Errorf("arXiv comment exported as venue:\n%s", out)
+	}
+}
+
+func TestWriteRIS(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatRIS, samplePapers()); err != nil {
+		t.Fatal(err)
+	}
+	records := strings.Split(buf.String(), "ER  - \n")
+	if len(records) != 3 {
+		t.Fatalf("got %d RIS records, want 2:\n%s", len(records)-1, buf.String())
+	}
+	for i, want := range [][]string{
+		{"TY  - CPAPER\n", "T2  - CVPR\n", "AU  - He, Kaiming\n", "PY  - 2016\n", "DA  - 2016/06/27\n", "DO  - 10.1109/cvpr.2016.90\n"},
+		{"TY  - UNPB\n", "AU  - Lovelace, Ada\n", "N1  - arXiv:2401.00001\n", "KW  - cs.LG\n"},
+	} {
+		for _, line := range want {
+			if !strings.Contains(records[i], line) {
+				t.Errorf("RIS record %d missing %q:\n%s", i, line, records[i])
+			}
+		}
+	}
+}
+
+func TestWriteCSLJSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSLJSON, samplePapers()); err != nil {
+		t.Fatal(err)
+	}
+	var items []cslItem
+	if err := json.Unmarshal(buf.Bytes(), &items); err != nil {
+		t.Fatalf("invalid CSL-JSON: %v", err)
+	}
+	want := []cslItem{
+		{
+			ID: "he2016deep", Type: "paper-conference", Title: "Deep Residual Learning & More",
+			Author:         []cslName{{Family: "He", Given: "Kaiming"}, {Family: "Sun", Given: "Jian"}},
+			Issued:         &cslDate{DateParts: [][]int{{2016, 6, 27}}},
+			ContainerTitle: "CVPR", DOI: "10.1109/cvpr.2016.90",
+		},
+		{
+			ID: "lovelace2024some", Type: "article", Title: "Some preprint",
+			Author:    []cslName{{Family: "Lovelace", Given: "Ada"}},
+			Issued:    &cslDate{DateParts: [][]int{{2024, 1, 2}}},
+			Publisher: "arXiv", Number: "arXiv:2401.00001", URL: "http://arxiv.org/abs/2401.00001v1",
+		},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("CSL-JSON = %+v, want %+v", items, want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, FormatCSV, samplePapers()); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	want := [][]string{
+		{"key", "title", "authors", "year", "venue", "ccf_class", "doi", "arxiv_id", "url", "published_at", "citations", "sources"},
+		{"he2016deep", "Deep Residual Learning & More", "Kaiming He; Jian Sun", "2016", "CVPR", "A", "10.1109/cvpr.2016.90", "", "", "2016-06-27", "100", "dblp"},
+		{"lovelace2024some", "Some preprint", "Ada Lovelace", "2024", "", "", "", "2401.00001", "http://arxiv.org/abs/2401.00001v1", "2024-01-02", "0", "arxiv;s2"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("CSV rows = %q, want %q", rows, want)
+	}
+}
+
+func TestWriteUnsupportedFormat(t *testing.T) {
+	if err := Write(&bytes.Buffer{}, "docx", nil); err == nil {
+		t.Error("Write with unknown format: want error")
+	}
+}
@@ -0,0 +1,341 @@
+package index
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"paper-scraper/internal/model"
+)
+
+// BM25 参数
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// 参与索引的字段及其权重（字段加权）
+const (
+	fieldTitle = iota
+	fieldAbstract
+	fieldAuthors
+	fieldVenue
+	fieldCategories
+	numFields
+)
+
+var fieldBoosts = [numFields]float64{
+	fieldTitle:      3.0,
+	fieldAbstract:   1.0,
+	fieldAuthors:    2.0,
+	fieldVenue:      1.5,
+	fieldCategories: 1.5,
+}
+
+// 单独出现时不参与打分的词（短语中仍按位置匹配）
+var queryStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "of": true, "for": true, "and": true,
+	"in": true, "on": true, "to": true, "with": true, "via": true, "by": true,
+}
+
+type fieldIndex struct {
+	postings map[string]map[int][]int // 词 -> 文档 -> 词位置
+	lengths  map[int]int              // 文档 -> 字段长度
+	totalLen int
+}
+
+type document struct {
+	key   string
+	paper model.Paper
+	terms [numFields][]string
+}
+
+// Index 是内存中的倒排索引，支持 BM25 打分、短语查询与字段加权
+type Index struct {
+	mu     sync.RWMutex
+	fields [numFields]*fieldIndex
+	docs   map[int]*document
+	keys   map[string]int // 文档键 -> 内部 ID
+	nextID int
+}
+
+func New() *Index {
+	ix := &Index{
+		docs: make(map[int]*document),
+		keys: make(map[string]int),
+	}
+	for f := range ix.fields {
+		ix.fields[f] = &fieldIndex{
+			postings: make(map[string]map[int][]int),
+			lengths:  make(map[int]int),
+		}
+	}
+	return ix
+}
+
+// Add 添加或替换键为 key 的文档
+func (ix *Index) Add(key string, p model.Paper) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if id, ok := ix.keys[key]; ok {
+		ix.remove(id)
+	}
+	id := ix.nextID
+	ix.nextID++
+
+	doc := &document{key: key, paper: p}
+	doc.terms[fieldTitle] = Tokenize(p.Title)
+	doc.terms[fieldAbstract] = Tokenize(p.Abstract)
+	doc.terms[fieldAuthors] = Tokenize(strings.Join(p.Authors, " ; "))
+	doc.terms[fieldVenue] = Tokenize(p.Venue)
+	doc.terms[fieldCategories] = Tokenize(strings.Join(p.Categories, " ; "))
+
+	for f, terms := range doc.terms {
+		fi := ix.fields[f]
+		for pos, t := range terms {
+			if fi.postings[t] == nil {
+				fi.postings[t] = make(map[int][]int)
+			}
+			fi.postings[t][id] = append(fi.postings[t][id], pos)
+		}
+		fi.lengths[id] = len(terms)
+		fi.totalLen += len(terms)
+	}
+	ix.docs[id] = doc
+	ix.keys[key] = id
+}
+
+func (ix *Index) remove(id int) {
+	doc, ok := ix.docs[id]
+	if !ok {
+		return
+	}
+	for f, terms := range doc.terms {
+		fi := ix.fields[f]
+		for _, t := range terms {
+			if docs := fi.postings[t]; docs != nil {
+				delete(docs, id)
+				if len(docs) == 0 {
+					delete(fi.postings, t)
+				}
+			}
+		}
+		fi.totalLen -= fi.lengths[id]
+		delete(fi.lengths, id)
+	}
+	delete(ix.keys, doc.key)
+	delete(ix.docs, id)
+}
+
+// Len 返回索引中的文档数量
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+// Hit 是一条检索结果
+type Hit struct {
+	Key   string
+	Paper model.Paper
+	Score float64
+}
+
+// Search 按 BM25 相关度检索。查询中用双引号包裹的部分作为短语，必须完整出现在某个字段中；
+// 其余词按 OR 语义参与打分。返回按得分降序排列的全部命中结果
+func (ix *Index) Search(query string) []Hit {
+	phrases, terms := parseQuery(query)
+	if len(phrases) == 0 && len(terms) == 0 {
+		return nil
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	scores := make(map[int]float64)
+
+	// 1. 普通词：BM25 分数按字段加权求和
+	for _, t := range terms {
+		ix.scoreTerm(t, scores, nil)
+	}
+
+	// 2. 短语：文档必须包含全部短语，短语中各词的分数计入总分
+	if len(phrases) > 0 {
+		var candidates map[int]bool
+		for i, phrase := range phrases {
+			matched := ix.matchPhrase(phrase)
+			if i == 0 {
+				candidates = matched
+				continue
+			}
+			for id := range candidates {
+				if !matched[id] {
+					delete(candidates, id)
+				}
+			}
+		}
+		for id := range scores {
+			if !candidates[id] {
+				delete(scores, id)
+			}
+		}
+		for _, phrase := range phrases {
+			for _, t := range phrase {
+				ix.scoreTerm(t, scores, candidates)
+			}
+		}
+	}
+
+	hits := make([]Hit, 0, len(scores))
+	for id, score := range scores {
+		doc := ix.docs[id]
+		hits = append(hits, Hit{Key: doc.key, Paper: doc.paper, Score: score})
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Key < hits[j].Key
+	})
+	return hits
+}
+
+// All 返回索引中的全部文档（顺序不定）
+func (ix *Index) All() []Hit {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	hits := make([]Hit, 0, len(ix.docs))
+	for _, doc := range ix.docs {
+		hits = append(hits, Hit{Key: doc.key, Paper: doc.paper})
+	}
+	return hits
+}
+
+// scoreTerm 将词 t 在各字段上的 BM25 分数累加到 scores；only 非 nil 时只为其中的文档打分
+func (ix *Index) scoreTerm(t string, scores map[int]float64, only map[int]bool) {
+	n := float64(len(ix.docs))
+	if n == 0 {
+		return
+	}
+	for f, fi := range ix.fields {
+		docs := fi.postings[t]
+		if len(docs) == 0 {
+			continue
+		}
+		df := float64(len(docs))
+		idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+		avgLen := float64(fi.totalLen) / n
+		if avgLen == 0 {
+			avgLen = 1
+		}
+		for id, positions := range docs {
+			if only != nil && !only[id] {
+				continue
+			}
+			tf := float64(len(positions))
+			norm := tf * (bm25K1 + 1) / (tf + bm25K1*(1-bm25B+bm25B*float64(fi.lengths[id])/avgLen))
+			scores[id] += fieldBoosts[f] * idf * norm
+		}
+	}
+}
+
+// matchPhrase 返回在任一字段中按顺序连续包含 phrase 的文档
+func (ix *Index) matchPhrase(phrase []string) map[int]bool {
+	matched := make(map[int]bool)
+	if len(phrase) == 0 {
+		return matched
+	}
+	for _, fi := range ix.fields {
+		first := fi.postings[phrase[0]]
+		for id, positions := range first {
+			if matched[id] {
+				continue
+			}
+			for _, start := range positions {
+				if phraseAt(fi, id, phrase, start) {
+					matched[id] = true
+					break
+				}
+			}
+		}
+	}
+	return matched
+}
+
+func phraseAt(fi *fieldIndex, id int, phrase []string, start int) bool {
+	for offset, t := range phrase[1:] {
+		found := false
+		for _, pos := range fi.postings[t][id] {
+			if pos == start+offset+1 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// parseQuery 拆分查询为短语（双引号内）与普通词
+func parseQuery(query string) (phrases [][]string, terms []string) {
+	parts := strings.Split(query, `"`)
+	for i, part := range parts {
+		tokens := Tokenize(part)
+		if i%2 == 1 {
+			if len(tokens) == 1 {
+				terms = append(terms, tokens[0])
+			} else if len(tokens) > 1 {
+				phrases = append(phrases, tokens)
+			}
+			continue
+		}
+		for _, t := range tokens {
+			if !queryStopWords[t] {
+				terms = append(terms, t)
+			}
+		}
+	}
+	return phrases, terms
+}
+
+// Tokenize 将文本切分为小写词（字母与数字序列）
+func Tokenize(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Rank 在给定的论文集合上构建临时索引，按与 query 的相关度重新排序。
+// 未命中的论文保持原有相对顺序排在最后
+func Rank(query string, papers []model.Paper) []model.Paper {
+	if strings.TrimSpace(query) == "" || len(papers) < 2 {
+		return papers
+	}
+	ix := New()
+	for i, p := range papers {
+		ix.Add(strconv.Itoa(i), p)
+	}
+	scores := make(map[string]float64)
+	for _, h := range ix.Search(query) {
+		scores[h.Key] = h.Score
+	}
+
+	order := make([]int, len(papers))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return scores[strconv.Itoa(order[a])] > scores[strconv.Itoa(order[b])]
+	})
+	ranked := make([]model.Paper, len(papers))
+	for i, idx := range order {
+		ranked[i] = papers[idx]
+	}
+	return ranked
+}
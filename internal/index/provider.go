@@ -0,0 +1,103 @@
+package index
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"time"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+	"paper-scraper/internal/query"
+)
+
+// Provider 将本地索引暴露为数据源，注册名为 "local"
+type Provider struct {
+	Index *Index
+}
+
+func NewProvider(ix *Index) *Provider {
+	return &Provider{Index: ix}
+}
+
+func (p *Provider) Name() string { return "local" }
+
+func (p *Provider) Capabilities() provider.Capabilities {
+	return provider.Capabilities{DateRange: true, Sort: true, Offset: true, Persistent: true}
+}
+
+func (p *Provider) Search(ctx context.Context, q provider.Query) ([]model.Paper, error) {
+	papers := p.matching(q)
+	if q.Offset >= len(papers) {
+		return nil, nil
+	}
+	papers = papers[q.Offset:]
+	if q.Limit > 0 && len(papers) > q.Limit {
+		papers = papers[:q.Limit]
+	}
+	return papers, nil
+}
+
+// SearchPage 以结果下标作为分页位置
+func (p *Provider) SearchPage(ctx context.Context, q provider.Query, cursor string) (provider.Page, error) {
+	offset, _ := strconv.Atoi(cursor)
+	papers := p.matching(q)
+	if offset >= len(papers) {
+		return provider.Page{}, nil
+	}
+	end := len(papers)
+	if q.Limit > 0 && offset+q.Limit < end {
+		end = offset + q.Limit
+	}
+	page := provider.Page{Papers: papers[offset:end]}
+	if end < len(papers) {
+		page.Next = strconv.Itoa(end)
+	}
+	return page, nil
+}
+
+// matching 返回满足查询条件的全部论文，按请求的方式排序
+func (p *Provider) matching(q provider.Query) []model.Paper {
+	var hits []Hit
+	if q.Text == "" {
+		hits = p.Index.All()
+	} else {
+		hits = p.Index.Search(q.Text)
+	}
+
+	// 日期范围过滤
+	var startT, endT time.Time
+	if q.StartDate != "" && q.EndDate != "" {
+		startT = provider.ParseDate(q.StartDate)
+		endT = provider.ParseDate(q.EndDate).Add(24*time.Hour - time.Nanosecond)
+	}
+	papers := make([]model.Paper, 0, len(hits))
+	for _, h := range hits {
+		if !startT.IsZero() {
+			pubT := provider.ParseDate(h.Paper.PublishedAt)
+			if pubT.IsZero() || pubT.Before(startT) || pubT.After(endT) {
+				continue
+			}
+		}
+		// BM25 只按关键词打分，字段、年份与否定条件在此过滤
+		if !query.Match(q.Expr, h.Paper) || !provider.MatchesCategories(h.Paper, q.Categories) {
+			continue
+		}
+		papers = append(papers, h.Paper)
+	}
+
+	// 有查询词且要求按相关度排序时保持 BM25 顺序，否则按发布时间排序
+	if q.Sort != "relevance" || q.Text == "" {
+		sort.SliceStable(papers, func(i, j int) bool {
+			if q.Sort == "published_asc" {
+				return papers[i].PublishedAt < papers[j].PublishedAt
+			}
+			if q.Sort == "citations" && papers[i].Citations != papers[j].Citations {
+				return papers[i].Citations > papers[j].Citations
+			}
+			return papers[i].PublishedAt > papers[j].PublishedAt
+		})
+	}
+
+	return papers
+}
@@ -0,0 +1,370 @@
+package merge
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+)
+
+// 标题相似度阈值（基于词集合的 Jaccard 系数），需同时满足第一作者姓氏一致
+const titleSimilarityThreshold = 0.9
+
+// Papers 对多源检索结果去重并融合：
+// 依次按 DOI、arXiv ID、规范化标题 + 第一作者相似度匹配同一篇论文，
+// 匹配到的记录融合为一条，保留各来源最好的字段，并记录全部来源。
+// 返回结果保持各组首次出现的顺序，不修改传入的切片。
+func Papers(papers []model.Paper) []model.Paper {
+	papers = append([]model.Paper(nil), papers...)
+	if len(papers) < 2 {
+		for i := range papers {
+			if len(papers[i].Sources) == 0 {
+				papers[i].Sources = []string{papers[i].Source}
+			}
+		}
+		return papers
+	}
+
+	parent := make([]int, len(papers))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	union := func(i, j int) {
+		ri, rj := find(i), find(j)
+		if ri == rj {
+			return
+		}
+		// 以较早出现的记录作为根，保证输出顺序稳定
+		if rj < ri {
+			ri, rj = rj, ri
+		}
+		parent[rj] = ri
+	}
+
+	// 1. 精确标识匹配
+	byDOI := make(map[string]int)
+	byArxiv := make(map[string]int)
+	byTitle := make(map[string]int)
+	keys := make([]titleKey, len(papers))
+	for i, p := range papers {
+		if doi := provider.NormalizeDOI(p.DOI); doi != "" {
+			if j, ok := byDOI[doi]; ok {
+				union(i, j)
+			} else {
+				byDOI[doi] = i
+			}
+		}
+		if id := arxivID(p); id != "" {
+			if j, ok := byArxiv[id]; ok {
+				union(i, j)
+			} else {
+				byArxiv[id] = i
+			}
+		}
+		keys[i] = newTitleKey(p)
+		if keys[i].normalized == "" {
+			continue
+		}
+		exact := keys[i].normalized + "|" + keys[i].firstAuthor
+		if j, ok := byTitle[exact]; ok {
+			union(i, j)
+		} else {
+			byTitle[exact] = i
+		}
+	}
+
+	// 2. 标题近似匹配（结果集通常只有数百条，两两比较即可）
+	for i := 0; i < len(papers); i++ {
+		if len(keys[i].tokens) == 0 {
+			continue
+		}
+		for j := i + 1; j < len(papers); j++ {
+			if find(i) == find(j) || len(keys[j].tokens) == 0 {
+				continue
+			}
+			if keys[i].firstAuthor != keys[j].firstAuthor {
+				continue
+			}
+			if jaccard(keys[i].tokens, keys[j].tokens) >= titleSimilarityThreshold {
+				union(i, j)
+			}
+		}
+	}
+
+	// 3. 分组并融合
+	groups := make(map[int][]model.Paper)
+	var order []int
+	for i, p := range papers {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			order = append(order, root)
+		}
+		groups[root] = append(groups[root], p)
+	}
+
+	merged := make([]model.Paper, 0, len(order))
+	for _, root := range order {
+		merged = append(merged, Fuse(groups[root]))
+	}
+	return merged
+}
+
+// Keys 返回论文的精确匹配键（DOI、arXiv ID、有作者时的规范化标题 + 第一作者姓氏），
+// 两条记录有任一键相同即视为同一论文。供分页时跨页去重使用
+func Keys(p model.Paper) []string {
+	var keys []string
+	if doi := provider.NormalizeDOI(p.DOI); doi != "" {
+		keys = append(keys, "doi:"+doi)
+	}
+	if id := arxivID(p); id != "" {
+		keys = append(keys, "arxiv:"+id)
+	}
+	if tk := newTitleKey(p); tk.normalized != "" {
+		keys = append(keys, "title:"+tk.normalized+"|"+tk.firstAuthor)
+	}
+	return keys
+}
+
+// Fuse 将已确认是同一论文的多条记录融合为一条
+func Fuse(group []model.Paper) model.Paper {
+	base := group[0]
+	// 优先以 arXiv 记录为主记录（稳定的 ID 与链接）
+	for _, p := range group {
+		if p.Source == "arxiv" {
+			base = p
+			break
+		}
+	}
+	out := base
+	out.Sources = nil
+	out.ExternalIDs = nil // 重新汇总，避免修改输入记录共享的 map
+	out.Institutions = nil
+
+	bestVenue := base
+	authorRecord := base
+	for _, p := range group {
+		for _, s := range sourcesOf(p) {
+			out.Sources = appendUnique(out.Sources, s)
+		}
+
+		if p.Citations > out.Citations {
+			out.Citations = p.Citations
+		}
+		if p.InfluentialCitations > out.InfluentialCitations {
+			out.InfluentialCitations = p.InfluentialCitations
+		}
+		if out.S2ID == "" {
+			out.S2ID = p.S2ID
+		}
+		if out.VenueType == "" {
+			out.VenueType = p.VenueType
+		}
+		if out.TLDR == "" {
+			out.TLDR = p.TLDR
+		}
+		if out.PDFURL == "" {
+			out.PDFURL = p.PDFURL
+		}
+		if len(p.Versions) > len(out.Versions) {
+			out.Versions = p.Versions
+		}
+		for _, inst := range p.Institutions {
+			out.Institutions = provider.AppendInstitution(out.Institutions, inst)
+		}
+		for k, v := range p.ExternalIDs {
+			if out.ExternalIDs == nil {
+				out.ExternalIDs = make(map[string]string)
+			}
+			if _, ok := out.ExternalIDs[k]; !ok {
+				out.ExternalIDs[k] = v
+			}
+		}
+		if len(p.Abstract) > len(out.Abstract) {
+			out.Abstract = p.Abstract
+		}
+		if len(p.Authors) > len(out.Authors) {
+			out.Authors = p.Authors
+			authorRecord = p
+		}
+		if out.Title == "" {
+			out.Title = p.Title
+		}
+		if out.URL == "" {
+			out.URL = p.URL
+		}
+		if out.ArxivID == "" {
+			out.ArxivID = arxivID(p)
+		}
+		if out.Comment == "" {
+			out.Comment = p.Comment
+		}
+		// 优先保留出版社 DOI，而非 arXiv 的 10.48550 DOI
+		if p.DOI != "" && (out.DOI == "" || isArxivDOI(out.DOI) && !isArxivDOI(p.DOI)) {
+			out.DOI = p.DOI
+		}
+		// 日期取最精确的；同等精度下取最早（通常是 arXiv 首次提交）
+		if betterDate(p.PublishedAt, out.PublishedAt) {
+			out.PublishedAt = p.PublishedAt
+			out.Year = p.Year
+		}
+		if out.Year == nil && p.Year != nil {
+			out.Year = p.Year
+		}
+		if venueRank(p) > venueRank(bestVenue) {
+			bestVenue = p
+		}
+	}
+
+	// 分类优先使用 arXiv 的学科分类
+	for _, p := range group {
+		if p.Source == "arxiv" && len(p.Categories) > 0 {
+			out.Categories = p.Categories
+			break
+		}
+		if len(out.Categories) == 0 {
+			out.Categories = p.Categories
+		}
+	}
+
+	// 作者以人数最多的记录为准，其余来源的作者先按标识符、再按规范化姓名对应，补全标识符与所属机构
+	out.AuthorDetails = nil
+	for _, p := range group {
+		if len(p.AuthorDetails) > 0 {
+			out.AuthorDetails = provider.AuthorsOf(authorRecord)
+			break
+		}
+	}
+	if out.AuthorDetails != nil {
+		for _, p := range group {
+			out.AuthorDetails = provider.ReconcileAuthors(out.AuthorDetails, provider.AuthorsOf(p))
+		}
+	}
+
+	out.Venue = bestVenue.Venue
+	out.CCFClass = bestVenue.CCFClass
+	return out
+}
+
+// venueRank 评估 venue 字段的可信度：DBLP 规范名 > 有 CCF 分级 > 期刊/会议名 > arXiv 注释
+func venueRank(p model.Paper) int {
+	rank := 0
+	switch p.Source {
+	case "dblp":
+		rank = 3
+	case "openalex", "s2":
+		rank = 2
+	case "arxiv":
+		rank = 1
+	}
+	if provider.IsPlaceholderVenue(p.Venue) {
+		return 0
+	}
+	if p.CCFClass != "" && p.CCFClass != "None" {
+		rank += 10
+	}
+	return rank
+}
+
+// betterDate 判断 candidate 是否比 current 更适合作为发布日期
+func betterDate(candidate, current string) bool {
+	if candidate == "" {
+		return false
+	}
+	if current == "" {
+		return true
+	}
+	if len(candidate) != len(current) {
+		return len(candidate) > len(current)
+	}
+	return candidate < current
+}
+
+func sourcesOf(p model.Paper) []string {
+	if len(p.Sources) > 0 {
+		return p.Sources
+	}
+	return []string{p.Source}
+}
+
+func isArxivDOI(doi string) bool {
+	return provider.ExtractArxivID(doi) != ""
+}
+
+func arxivID(p model.Paper) string {
+	if p.ArxivID != "" {
+		return p.ArxivID
+	}
+	if id := provider.ExtractArxivID(p.ID); id != "" {
+		return id
+	}
+	return provider.ExtractArxivID(p.DOI)
+}
+
+// titleKey 是标题匹配用的键。没有作者时为空：仅凭标题（如 "Introduction"）无法区分不同论文
+type titleKey struct {
+	normalized  string
+	tokens      map[string]bool
+	firstAuthor string
+}
+
+func newTitleKey(p model.Paper) titleKey {
+	if len(p.Authors) == 0 || surname(p.Authors[0]) == "" {
+		return titleKey{}
+	}
+	words := strings.FieldsFunc(strings.ToLower(p.Title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	key := titleKey{
+		normalized: strings.Join(words, " "),
+		tokens:     make(map[string]bool, len(words)),
+	}
+	for _, w := range words {
+		key.tokens[w] = true
+	}
+	key.firstAuthor = surname(p.Authors[0])
+	return key
+}
+
+// surname 提取作者姓氏（取最后一个单词，兼容 "Last, First" 格式）
+func surname(name string) string {
+	name = strings.TrimSpace(name)
+	if idx := strings.Index(name, ","); idx > 0 {
+		name = name[:idx]
+	} else if fields := strings.Fields(name); len(fields) > 0 {
+		name = fields[len(fields)-1]
+	}
+	return strings.ToLower(strings.Trim(name, ".-"))
+}
+
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	inter := 0
+	for w := range a {
+		if b[w] {
+			inter++
+		}
+	}
+	return float64(inter) / float64(len(a)+len(b)-inter)
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	list = append(list, s)
+	sort.Strings(list)
+	return list
+}
@@ -0,0 +1,150 @@
+package merge
+
+import (
+	"reflect"
+	"testing"
+
+	"paper-scraper/internal/model"
+)
+
+func TestPapers(t *testing.T) {
+	long := "Scalable diffusion models with transformers for high resolution image synthesis"
+	tests := []struct {
+		name   string
+		papers []model.Paper
+		groups [][]string // 按输出顺序，每组融合后的来源
+	}{
+		{
+			name: "same doi",
+			papers: []model.Paper{
+				{Source: "openalex", Title: "A", DOI: "https://doi.org/10.1000/X"},
+				{Source: "dblp", Title: "B", DOI: "10.1000/x"},
+			},
+			groups: [][]string{{"dblp", "openalex"}},
+		},
+		{
+			name: "arxiv id and arxiv doi",
+			papers: []model.Paper{
+				{Source: "arxiv", Title: "A", ArxivID: "2401.00001"},
+				{Source: "openalex", Title: "B", DOI: "10.48550/arXiv.2401.00001"},
+			},
+			groups: [][]string{{"arxiv", "openalex"}},
+		},
+		{
+			name: "similar title same first author",
+			papers: []model.Paper{
+				{Source: "arxiv", Title: long, Authors: []string{"William Peebles", "Saining Xie"}},
+				{Source: "dblp", Title: long + " (extended)", Authors: []string{"Peebles, William"}},
+			},
+			groups: [][]string{{"arxiv", "dblp"}},
+		},
+		{
+			name: "similar title different first author",
+			papers: []model.Paper{
+				{Source: "arxiv", Title: long, Authors: []string{"William Peebles"}},
+				{Source: "dblp", Title: long, Authors: []string{"Saining Xie"}},
+			},
+			groups: [][]string{{"arxiv"}, {"dblp"}},
+		},
+		{
+			name: "same title without authors",
+			papers: []model.Paper{
+				{Source: "openalex", Title: "Introduction"},
+				{Source: "dblp", Title: "Introduction"},
+			},
+			groups: [][]string{{"openalex"}, {"dblp"}},
+		},
+		{
+			name: "transitive match keeps first appearance order",
+			papers: []model.Paper{
+				{Source: "s2", Title: "Other paper", Authors: []string{"Ada Lovelace"}},
+				{Source: "openalex", Title: "A", DOI: "10.1000/y", ArxivID: "2401.00002"},
+				{Source: "arxiv", Title: "A", ArxivID: "2401.00002"},
+				{Source: "dblp", Title: "A", DOI: "10.1000/Y"},
+			},
+			groups: [][]string{{"s2"}, {"arxiv", "dblp", "openalex"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Papers(tt.papers)
+			var groups [][]string
+			for _, p := range got {
+				groups = append(groups, p.Sources)
+			}
+			if !reflect.DeepEqual(groups, tt.groups) {
+				t.Errorf("Papers sources = %v, want %v", groups, tt.groups)
+			}
+		})
+	}
+}
+
+func TestPapersDoesNotMutateInput(t *testing.T) {
+	papers := []model.Paper{
+		{Source: "arxiv", Title: "A", ArxivID: "2401.00001"},
+		{Source: "s2", Title: "A", ArxivID: "2401.00001", Citations: 10},
+	}
+	Papers(papers)
+	if papers[0].Sources != nil || papers[1].Sources != nil || papers[0].Citations != 0 {
+		t.Errorf("input modified: %+v", papers)
+	}
+}
+
+func TestFuse(t *testing.T) {
+	year2023, year2024 := 2023, 2024
+	got := Fuse([]model.Paper{
+		{
+			Source: "openalex", Title: "Attention", DOI: "10.1000/attn", Citations: 120,
+			Authors: []string{"A. Vaswani", "N. Shazeer", "N. Parmar"}, Venue: "NeurIPS", CCFClass: "A",
+			PublishedAt: "2024-01-15", Year: &year2024, ExternalIDs: map[string]string{"openalex": "W1"},
+		},
+		{
+			Source: "arxiv", Title: "Attention", ArxivID: "2306.00001", DOI: "10.48550/arXiv.2306.00001",
+			Authors: []string{"Ashish Vaswani"}, Abstract: "The dominant sequence transduction models...",
+			Venue: "15 pages", Comment: "15 pages", PublishedAt: "2023-06-01", Year: &year2023,
+			Categories: []string{"cs.CL"}, URL: "http://arxiv.org/abs/2306.00001v1",
+		},
+		{Source: "s2", Title: "Attention", S2ID: "abc", Citations: 150, Venue: "NeurIPS"},
+	})
+
+	want := map[string][2]any{
+		"base ID":     {got.ArxivID, "2306.00001"},
+		"url":         {got.URL, "http://arxiv.org/abs/2306.00001v1"},
+		"publisher":   {got.DOI, "10.1000/attn"},
+		"citations":   {got.Citations, 150},
+		"s2 id":       {got.S2ID, "abc"},
+		"venue":       {got.Venue, "NeurIPS"},
+		"ccf":         {got.CCFClass, "A"},
+		"earliest":    {got.PublishedAt, "2023-06-01"},
+		"year":        [2]any{*got.Year, 2023},
+		"authors":     [2]any{len(got.Authors), 3},
+		"abstract":    {got.Abstract, "The dominant sequence transduction models..."},
+		"comment":     {got.Comment, "15 pages"},
+		"categories":  {got.Categories[0], "cs.CL"},
+		"external id": {got.ExternalIDs["openalex"], "W1"},
+	}
+	for name, pair := range want {
+		if pair[0] != pair[1] {
+			t.Errorf("%s = %v, want %v", name, pair[0], pair[1])
+		}
+	}
+	if !reflect.DeepEqual(got.Sources, []string{"arxiv", "openalex", "s2"}) {
+		t.Errorf("sources = %v", got.Sources)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		paper model.Paper
+		want  []string
+	}{
+		{model.Paper{Title: "Introduction"}, nil},
+		{model.Paper{Title: "Deep Learning!", Authors: []string{"LeCun, Yann"}}, []string{"title:deep learning|lecun"}},
+		{model.Paper{DOI: "10.48550/arXiv.2401.00001v2"}, []string{"doi:10.48550/arxiv.2401.00001v2", "arxiv:2401.00001"}},
+	}
+	for _, tt := range tests {
+		if got := Keys(tt.paper); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Keys(%+v) = %q, want %q", tt.paper, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,13 @@
 package model
 
+import "encoding/json"
+
 // --- 数据模型 ---
 
 type Paper struct {
 	ID          string   `json:"id"`
 	Title       string   `json:"title"`
-	Authors     []string `json:"authors"`
+	Authors     []string `json:"authors"` // 作者姓名，与 AuthorDetails 一一对应
 	Venue       string   `json:"venue"`
 	Year        *int     `json:"year"`
 	Abstract    string   `json:"abstract"`
@@ -15,14 +17,76 @@ type Paper struct {
 	PublishedAt string   `json:"published_at"`
 	Citations   int      `json:"citations"`
 	CCFClass    string   `json:"ccf_class"`
+	DOI         string   `json:"doi,omitempty"`
+	ArxivID     string   `json:"arxiv_id,omitempty"`
+	Comment     string   `json:"comment,omitempty"` // arXiv 作者注释，例如 "10 pages, 5 figures"
+	Sources     []string `json:"sources,omitempty"` // 合并后记录的全部来源
+
+	// 以下字段由 Semantic Scholar 补全（enrich=s2）
+	InfluentialCitations int               `json:"influential_citations,omitempty"`
+	VenueType            string            `json:"venue_type,omitempty"` // journal / conference 等
+	S2ID                 string            `json:"s2_id,omitempty"`
+	ExternalIDs          map[string]string `json:"external_ids,omitempty"` // 例如 DBLP、MAG、CorpusId
+	TLDR                 string            `json:"tldr,omitempty"`         // S2 生成的一句话摘要
+	PDFURL               string            `json:"pdf_url,omitempty"`      // 开放获取的 PDF 链接
+
+	Versions     []PaperVersion `json:"versions,omitempty"`     // arXiv 版本历史（OAI-PMH 摄取）
+	Institutions []Institution  `json:"institutions,omitempty"` // 作者所属机构（OpenAlex authorships、arXiv affiliation）
+
+	AuthorDetails []Author `json:"author_details,omitempty"` // 结构化作者：标识符与所属机构
+}
+
+// Author 是论文的一位作者。标识符均为不带 URL 前缀的形式
+type Author struct {
+	Name         string        `json:"name"`
+	ORCID        string        `json:"orcid,omitempty"`       // 例如 0000-0002-1825-0097
+	OpenAlexID   string        `json:"openalex_id,omitempty"` // 例如 A5023888391
+	S2ID         string        `json:"s2_id,omitempty"`       // Semantic Scholar authorId
+	DBLPID       string        `json:"dblp_id,omitempty"`     // DBLP pid，例如 123/4567
+	Affiliations []Institution `json:"affiliations,omitempty"`
+}
+
+// Institution 是作者所属的机构
+type Institution struct {
+	Name    string `json:"name"`
+	ROR     string `json:"ror,omitempty"`     // ROR ID，例如 https://ror.org/042nb2s44
+	Country string `json:"country,omitempty"` // ISO 3166-1 两位国家代码
+}
+
+// PaperVersion 是 arXiv 论文的一个版本
+type PaperVersion struct {
+	Version string `json:"version"` // v1、v2 …
+	Date    string `json:"date"`    // RFC 3339
 }
 
 type PaperResponse struct {
-	Count       int     `json:"count"`
-	Items       []Paper `json:"items"`
-	Translation string  `json:"translation,omitempty"`
+	Count       int            `json:"count"`
+	Items       []Paper        `json:"items"`
+	Translation string         `json:"translation,omitempty"`
+	Sources     []SourceStatus `json:"sources"`
+	Enrichment  []SourceStatus `json:"enrichment,omitempty"`  // 补全阶段（如 s2）的执行情况
+	NextCursor  string         `json:"next_cursor,omitempty"` // 下一页的游标，为空表示没有更多结果
+	NextOffset  *int           `json:"next_offset,omitempty"` // 偏移分页时下一页的 offset，为空表示没有更多结果
 }
 
+// SourceStatus 记录单个数据源在一次请求中的执行情况，
+// 用于区分“没有结果”与“数据源故障”
+type SourceStatus struct {
+	Source    string `json:"source"`
+	Status    string `json:"status"` // ok / error / timeout
+	LatencyMs int64  `json:"latency_ms"`
+	Count     int    `json:"count"`
+	Error     string `json:"error,omitempty"`
+	Fallback  string `json:"fallback,omitempty"` // 上游失败时结果的替代来源，例如 "store"
+	Cache     string `json:"cache,omitempty"`    // 响应缓存状态：hit / miss / stale
+}
+
+const (
+	SourceStatusOK      = "ok"
+	SourceStatusError   = "error"
+	SourceStatusTimeout = "timeout" // 超过请求总时限仍未返回
+)
+
 // --- ArXiv XML Structs ---
 
 type AtomFeed struct {
@@ -40,10 +104,12 @@ type AtomEntry struct {
 	// ArXiv specific fields need namespace handling
 	Comment    string `xml:"http://arxiv.org/schemas/atom comment"`
 	JournalRef string `xml:"http://arxiv.org/schemas/atom journal_ref"`
+	DOI        string `xml:"http://arxiv.org/schemas/atom doi"`
 }
 
 type AtomAuthor struct {
-	Name string `xml:"name"`
+	Name        string   `xml:"name"`
+	Affiliation []string `xml:"http://arxiv.org/schemas/atom affiliation"`
 }
 
 type AtomLink struct {
@@ -60,19 +126,31 @@ type AtomCat struct {
 type OAWork struct {
 	ID               string           `json:"id"`
 	DisplayName      string           `json:"display_name"`
+	DOI              string           `json:"doi"`
 	Authorships      []OAAuthorship   `json:"authorships"`
 	PublicationYear  int              `json:"publication_year"`
 	PublicationDate  string           `json:"publication_date"`
 	Concepts         []OAConcept      `json:"concepts"`
 	PrimaryLocation  OALocation       `json:"primary_location"`
+	Locations        []OALocation     `json:"locations"`
 	CitedByCount     int              `json:"cited_by_count"`
 	AbstractInverted map[string][]int `json:"abstract_inverted_index"`
+	ReferencedWorks  []string         `json:"referenced_works"`
 }
 
 type OAAuthorship struct {
 	Author struct {
+		ID          string `json:"id"`
 		DisplayName string `json:"display_name"`
+		ORCID       string `json:"orcid"`
 	} `json:"author"`
+	Institutions []OAInstitution `json:"institutions"`
+}
+
+type OAInstitution struct {
+	DisplayName string `json:"display_name"`
+	ROR         string `json:"ror"`
+	CountryCode string `json:"country_code"`
 }
 
 type OAConcept struct {
@@ -87,5 +165,103 @@ type OALocation struct {
 }
 
 type OAResponse struct {
+	Meta    OAMeta   `json:"meta"`
 	Results []OAWork `json:"results"`
 }
+
+type OAMeta struct {
+	Count      int     `json:"count"`
+	NextCursor *string `json:"next_cursor"` // 仅在使用 cursor 分页时返回，最后一页为 null
+}
+
+// OAAuthor 是 OpenAlex /authors 接口返回的作者
+type OAAuthor struct {
+	ID           string `json:"id"`
+	DisplayName  string `json:"display_name"`
+	ORCID        string `json:"orcid"`
+	WorksCount   int    `json:"works_count"`
+	CitedByCount int    `json:"cited_by_count"`
+	SummaryStats struct {
+		HIndex int `json:"h_index"`
+	} `json:"summary_stats"`
+	LastKnownInstitutions []OAInstitution `json:"last_known_institutions"`
+}
+
+type OAAuthorResponse struct {
+	Meta    OAMeta     `json:"meta"`
+	Results []OAAuthor `json:"results"`
+}
+
+// --- DBLP JSON 结构体 ---
+
+type DBLPResponse struct {
+	Result struct {
+		Hits struct {
+			Total string    `json:"@total"`
+			Hit   []DBLPHit `json:"hit"`
+		} `json:"hits"`
+	} `json:"result"`
+}
+
+type DBLPHit struct {
+	Info DBLPInfo `json:"info"`
+}
+
+type DBLPInfo struct {
+	Authors struct {
+		Author DBLPAuthors `json:"author"`
+	} `json:"authors"`
+	Title string      `json:"title"`
+	Venue DBLPStrings `json:"venue"`
+	Year  string      `json:"year"`
+	Type  string      `json:"type"`
+	Key   string      `json:"key"`
+	DOI   string      `json:"doi"`
+	EE    DBLPStrings `json:"ee"`
+	URL   string      `json:"url"`
+}
+
+type DBLPAuthor struct {
+	PID  string `json:"@pid"`
+	Text string `json:"text"`
+}
+
+// DBLPAuthors 兼容 DBLP 在只有一位作者时返回单个对象而非数组的情况
+type DBLPAuthors []DBLPAuthor
+
+func (a *DBLPAuthors) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		var list []DBLPAuthor
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		*a = list
+		return nil
+	}
+	var single DBLPAuthor
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*a = DBLPAuthors{single}
+	return nil
+}
+
+// DBLPStrings 兼容字段既可能是字符串也可能是字符串数组的情况（如 venue、ee）
+type DBLPStrings []string
+
+func (s *DBLPStrings) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '[' {
+		var list []string
+		if err := json.Unmarshal(data, &list); err != nil {
+			return err
+		}
+		*s = list
+		return nil
+	}
+	var single string
+	if err := json.Unmarshal(data, &single); err != nil {
+		return err
+	}
+	*s = DBLPStrings{single}
+	return nil
+}
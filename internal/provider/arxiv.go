@@ -1,31 +1,88 @@
 package provider
 
 import (
+	"context"
 	"encoding/xml"
 	"fmt"
 	"io"
-	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"time"
 
 	"paper-scraper/internal/model"
+	"paper-scraper/internal/query"
 )
 
-func FetchArxiv(query string, limit, offset int, startDate, endDate string, sortOrder string) ([]model.Paper, error) {
+type arxivProvider struct{}
+
+func (arxivProvider) Name() string { return "arxiv" }
+
+func (arxivProvider) Capabilities() Capabilities {
+	return Capabilities{DateRange: true, Sort: true, Offset: true}
+}
+
+func (a arxivProvider) Search(ctx context.Context, q Query) ([]model.Paper, error) {
+	page, err := a.SearchPage(ctx, q, strconv.Itoa(q.Offset))
+	return page.Papers, err
+}
+
+// SearchPage 以 arXiv 的 start 下标作为分页位置
+func (arxivProvider) SearchPage(ctx context.Context, q Query, cursor string) (Page, error) {
+	start, _ := strconv.Atoi(cursor)
+	var include, exclude []string
+	var residual query.Node
+	if q.Expr == nil {
+		if q.Text != "" {
+			include = append(include, fmt.Sprintf("all:%s", q.Text))
+		}
+	} else {
+		include, exclude, residual = arxivSearchParts(q.Expr)
+	}
+	papers, err := fetchArxiv(ctx, q.Categories, include, exclude, q.Limit, start, q.StartDate, q.EndDate, q.Sort)
+	if err != nil {
+		return Page{}, err
+	}
+	// 下一页位置按上游返回的条数计算；arXiv 无法表达的条件（如 venue）在结果上后置过滤
+	return Page{
+		Papers: query.Filter(residual, papers),
+		Next:   nextOffset(start, len(papers), q.Limit),
+	}, nil
+}
+
+func FetchArxiv(ctx context.Context, query string, limit, offset int, startDate, endDate string, sortOrder string) ([]model.Paper, error) {
+	var include []string
+	if query != "" {
+		include = append(include, fmt.Sprintf("all:%s", query))
+	}
+	return fetchArxiv(ctx, nil, include, nil, limit, offset, startDate, endDate, sortOrder)
+}
+
+// fetchArxiv 以 AND 连接 include 中的条件，并以 ANDNOT 排除 exclude 中的条件。
+// categories 限定 arXiv 分类（满足其一），为空时检索整个 cs.*
+func fetchArxiv(ctx context.Context, categories, include, exclude []string, limit, offset int, startDate, endDate string, sortOrder string) ([]model.Paper, error) {
 	searchParts := []string{"cat:cs.*"}
+	if len(categories) > 0 {
+		cats := make([]string, len(categories))
+		for i, c := range categories {
+			cats[i] = "cat:" + c
+		}
+		searchParts[0] = "(" + strings.Join(cats, " OR ") + ")"
+	}
 	if startDate != "" && endDate != "" {
 		start := strings.ReplaceAll(startDate, "-", "") + "0000"
 		end := strings.ReplaceAll(endDate, "-", "") + "2359"
 		searchParts = append(searchParts, fmt.Sprintf("submittedDate:[%s TO %s]", start, end))
 	}
-	if query != "" {
-		searchParts = append(searchParts, fmt.Sprintf("all:%s", query))
+	searchParts = append(searchParts, include...)
+
+	searchQuery := strings.Join(searchParts, " AND ")
+	for _, part := range exclude {
+		searchQuery += " ANDNOT " + part
 	}
 
 	params := url.Values{}
-	params.Set("search_query", strings.Join(searchParts, "+AND+"))
+	params.Set("search_query", searchQuery)
 	params.Set("start", strconv.Itoa(offset))
 	params.Set("max_results", strconv.Itoa(limit))
 	params.Set("sortBy", "submittedDate")
@@ -35,12 +92,13 @@ func FetchArxiv(query string, limit, offset int, startDate, endDate string, sort
 	} else {
 		params.Set("sortOrder", "descending")
 	}
+	if sortOrder == "relevance" && len(include) > 0 {
+		params.Set("sortBy", "relevance")
+	}
 
 	apiURL := "http://export.arxiv.org/api/query?" + params.Encode()
-	apiURL = strings.ReplaceAll(apiURL, "%2BAND%2B", "+AND+")
 
-	client := http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get(apiURL)
+	resp, err := httpGet(ctx, apiURL, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -58,9 +116,15 @@ func FetchArxiv(query string, limit, offset int, startDate, endDate string, sort
 
 	var papers []model.Paper
 	for _, entry := range feed.Entries {
-		var authors []string
+		var authors []model.Author
+		var institutions []model.Institution
 		for _, a := range entry.Authors {
-			authors = append(authors, a.Name)
+			author := model.Author{Name: a.Name}
+			for _, aff := range a.Affiliation {
+				author.Affiliations = AppendInstitution(author.Affiliations, model.Institution{Name: aff})
+				institutions = AppendInstitution(institutions, model.Institution{Name: aff})
+			}
+			authors = append(authors, author)
 		}
 		var categories []string
 		for _, c := range entry.Category {
@@ -86,18 +150,23 @@ func FetchArxiv(query string, limit, offset int, startDate, endDate string, sort
 		abstract := strings.TrimSpace(strings.ReplaceAll(entry.Summary, "\n", " "))
 
 		paper := model.Paper{
-			ID:          entry.ID,
-			Title:       title,
-			Authors:     authors,
-			Venue:       venue,
-			Year:        &year,
-			Abstract:    abstract,
-			URL:         entry.ID,
-			Source:      "arxiv",
-			Categories:  categories,
-			PublishedAt: entry.Published,
-			Citations:   0,
-			CCFClass:    GetCCFClass(venue),
+			ID:            entry.ID,
+			Title:         title,
+			Authors:       AuthorNames(authors),
+			AuthorDetails: authors,
+			Venue:         venue,
+			Year:          &year,
+			Abstract:      abstract,
+			URL:           entry.ID,
+			Source:        "arxiv",
+			Categories:    categories,
+			PublishedAt:   entry.Published,
+			Citations:     0,
+			CCFClass:      GetCCFClass(venue),
+			DOI:           NormalizeDOI(entry.DOI),
+			ArxivID:       ExtractArxivID(entry.ID),
+			Comment:       entry.Comment,
+			Institutions:  institutions,
 		}
 		for _, l := range entry.Links {
 			if l.Rel == "alternate" {
@@ -110,3 +179,97 @@ func FetchArxiv(query string, limit, offset int, startDate, endDate string, sort
 	}
 	return papers, nil
 }
+
+// IsCommentVenue 判断 venue 是否取自 arXiv 作者注释：没有 journal_ref 的 arXiv 记录以注释代替 venue，
+// 例如 "10 pages, 5 figures"，并不是发表渠道
+func IsCommentVenue(p model.Paper) bool {
+	return p.Comment != "" && p.Venue == p.Comment
+}
+
+// arXiv 检索字段前缀；venue 无对应字段
+var arxivFieldPrefixes = map[string]string{
+	query.FieldAny:      "all",
+	query.FieldAuthor:   "au",
+	query.FieldTitle:    "ti",
+	query.FieldAbstract: "abs",
+	query.FieldCategory: "cat",
+}
+
+// arxivSearchParts 将检索表达式翻译为 arXiv search_query 条件：
+// include 以 AND 连接，exclude 以 ANDNOT 排除，无法表达的条件放入 residual
+func arxivSearchParts(expr query.Node) (include, exclude []string, residual query.Node) {
+	var rest []query.Node
+	for _, c := range query.Conjuncts(expr) {
+		if not, ok := c.(*query.Not); ok {
+			if s, ok := arxivExpr(not.Child); ok {
+				exclude = append(exclude, s)
+				continue
+			}
+		} else if s, ok := arxivExpr(c); ok {
+			include = append(include, s)
+			continue
+		}
+		rest = append(rest, c)
+	}
+	return include, exclude, query.AndOf(rest)
+}
+
+func arxivExpr(n query.Node) (string, bool) {
+	switch v := n.(type) {
+	case *query.Term:
+		prefix, ok := arxivFieldPrefixes[v.Field]
+		if !ok {
+			return "", false
+		}
+		value := strings.NewReplacer(`"`, "", "(", " ", ")", " ").Replace(v.Value)
+		if v.Phrase || strings.Contains(strings.TrimSpace(value), " ") {
+			value = `"` + strings.TrimSpace(value) + `"`
+		}
+		return prefix + ":" + value, true
+	case *query.YearRange:
+		from, to := v.From, v.To
+		if from == 0 {
+			from = 1991 // arXiv 创立年份
+		}
+		if to == 0 {
+			to = time.Now().Year()
+		}
+		return fmt.Sprintf("submittedDate:[%d01010000 TO %d12312359]", from, to), true
+	case *query.And:
+		var pos, neg []string
+		for _, c := range v.Children {
+			if not, ok := c.(*query.Not); ok {
+				s, ok := arxivExpr(not.Child)
+				if !ok {
+					return "", false
+				}
+				neg = append(neg, s)
+				continue
+			}
+			s, ok := arxivExpr(c)
+			if !ok {
+				return "", false
+			}
+			pos = append(pos, s)
+		}
+		if len(pos) == 0 {
+			return "", false
+		}
+		s := strings.Join(pos, " AND ")
+		for _, n := range neg {
+			s += " ANDNOT " + n
+		}
+		return "(" + s + ")", true
+	case *query.Or:
+		parts := make([]string, 0, len(v.Children))
+		for _, c := range v.Children {
+			s, ok := arxivExpr(c)
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, s)
+		}
+		return "(" + strings.Join(parts, " OR ") + ")", true
+	}
+	return "", false
+}
@@ -0,0 +1,327 @@
+package provider
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"paper-scraper/internal/model"
+)
+
+// arXiv OAI-PMH 接口。与 Atom 检索接口不同，ListRecords 按日期完整列出某个集合（如 cs）
+// 新增或更新的全部记录，没有 max_results 上限，适合每日全量摄取
+var arxivOAIBaseURL = "https://oaipmh.arxiv.org/oai"
+
+// ConfigureArxivOAI 设置 OAI-PMH 接口地址，空串保持默认
+func ConfigureArxivOAI(baseURL string) {
+	if baseURL != "" {
+		arxivOAIBaseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// OAIResponse 是 OAI-PMH 响应
+type OAIResponse struct {
+	XMLName     xml.Name       `xml:"OAI-PMH"`
+	Error       *OAIError      `xml:"error"`
+	ListRecords OAIListRecords `xml:"ListRecords"`
+}
+
+type OAIError struct {
+	Code    string `xml:"code,attr"`
+	Message string `xml:",chardata"`
+}
+
+type OAIListRecords struct {
+	Records         []OAIRecord        `xml:"record"`
+	ResumptionToken OAIResumptionToken `xml:"resumptionToken"`
+}
+
+type OAIResumptionToken struct {
+	Token            string `xml:",chardata"`
+	Cursor           int    `xml:"cursor,attr"`
+	CompleteListSize int    `xml:"completeListSize,attr"`
+}
+
+type OAIRecord struct {
+	Header struct {
+		Identifier string   `xml:"identifier"`
+		Datestamp  string   `xml:"datestamp"`
+		SetSpecs   []string `xml:"setSpec"`
+		Status     string   `xml:"status,attr"` // deleted 表示记录已删除
+	} `xml:"header"`
+	Raw *ArxivRaw `xml:"metadata>arXivRaw"`
+}
+
+// ArxivRaw 是 arXivRaw 元数据格式，包含完整的版本历史
+type ArxivRaw struct {
+	ID         string         `xml:"id"`
+	Submitter  string         `xml:"submitter"`
+	Versions   []ArxivVersion `xml:"version"`
+	Title      string         `xml:"title"`
+	Authors    string         `xml:"authors"`
+	Categories string         `xml:"categories"`
+	Comments   string         `xml:"comments"`
+	JournalRef string         `xml:"journal-ref"`
+	DOI        string         `xml:"doi"`
+	License    string         `xml:"license"`
+	Abstract   string         `xml:"abstract"`
+}
+
+type ArxivVersion struct {
+	Version string `xml:"version,attr"` // v1、v2 …
+	Date    string `xml:"date"`         // RFC 1123，例如 Mon, 1 Jan 2024 00:00:01 GMT
+	Size    string `xml:"size"`
+}
+
+// HarvestStats 汇总一次 OAI-PMH 摄取
+type HarvestStats struct {
+	Records int `json:"records"` // 收到的记录数（含已删除）
+	Papers  int `json:"papers"`  // 交给回调的论文数
+	Deleted int `json:"deleted"`
+	Pages   int `json:"pages"`
+}
+
+// HarvestArxiv 通过 OAI-PMH ListRecords 摄取 set（如 cs）在 [from, until]（YYYY-MM-DD，按记录的
+// datestamp，可为空）内新增或更新的全部记录，按 resumptionToken 逐页获取，每页的论文交给 fn 处理。
+// 记录的 datestamp 是最近一次更新的日期，PublishedAt 取 v1 的提交日期，版本历史保存在 Versions 中
+func HarvestArxiv(ctx context.Context, set, from, until string, fn func([]model.Paper) error) (HarvestStats, error) {
+	var stats HarvestStats
+	params := url.Values{}
+	params.Set("verb", "ListRecords")
+	params.Set("metadataPrefix", "arXivRaw")
+	if set != "" {
+		params.Set("set", set)
+	}
+	if from != "" {
+		params.Set("from", from)
+	}
+	if until != "" {
+		params.Set("until", until)
+	}
+
+	for {
+		resp, err := fetchOAIPage(ctx, arxivOAIBaseURL+"?"+params.Encode())
+		if err != nil {
+			return stats, err
+		}
+		if resp.Error != nil {
+			// 时间范围内没有记录不是错误
+			if resp.Error.Code == "noRecordsMatch" {
+				return stats, nil
+			}
+			return stats, fmt.Errorf("oai-pmh %s: %s", resp.Error.Code, strings.TrimSpace(resp.Error.Message))
+		}
+		stats.Pages++
+
+		var papers []model.Paper
+		for _, rec := range resp.ListRecords.Records {
+			stats.Records++
+			if rec.Header.Status == "deleted" || rec.Raw == nil {
+				stats.Deleted++
+				continue
+			}
+			papers = append(papers, arxivRawToPaper(rec.Raw))
+		}
+		if len(papers) > 0 {
+			if err := fn(papers); err != nil {
+				return stats, err
+			}
+			stats.Papers += len(papers)
+		}
+
+		// resumptionToken 为空表示列表结束；后续请求只能携带 verb 与 resumptionToken
+		token := strings.TrimSpace(resp.ListRecords.ResumptionToken.Token)
+		if token == "" {
+			return stats, nil
+		}
+		params = url.Values{}
+		params.Set("verb", "ListRecords")
+		params.Set("resumptionToken", token)
+	}
+}
+
+func fetchOAIPage(ctx context.Context, rawURL string) (*OAIResponse, error) {
+	resp, err := httpGet(ctx, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+	var oai OAIResponse
+	if err := xml.NewDecoder(resp.Body).Decode(&oai); err != nil {
+		return nil, err
+	}
+	return &oai, nil
+}
+
+// arXivRaw 版本日期的格式，日期不补零，因此不能直接使用 time.RFC1123
+const arxivVersionDate = "Mon, 2 Jan 2006 15:04:05 MST"
+
+func arxivRawToPaper(raw *ArxivRaw) model.Paper {
+	venue := "arXiv"
+	if raw.JournalRef != "" {
+		venue = collapseSpace(raw.JournalRef)
+	} else if raw.Comments != "" {
+		venue = collapseSpace(raw.Comments)
+	}
+
+	var versions []model.PaperVersion
+	for _, v := range raw.Versions {
+		date := v.Date
+		if t, err := time.Parse(arxivVersionDate, strings.TrimSpace(v.Date)); err == nil {
+			date = t.UTC().Format(time.RFC3339)
+		}
+		versions = append(versions, model.PaperVersion{Version: v.Version, Date: date})
+	}
+
+	// 首次提交（v1）的日期作为发布日期
+	published := ""
+	if len(versions) > 0 {
+		published = versions[0].Date
+	}
+	var year *int
+	if t := ParseDate(published); !t.IsZero() {
+		y := t.Year()
+		year = &y
+	}
+	authors := splitArxivAuthors(raw.Authors)
+	var institutions []model.Institution
+	for _, a := range authors {
+		for _, inst := range a.Affiliations {
+			institutions = AppendInstitution(institutions, inst)
+		}
+	}
+	latest := ""
+	if len(raw.Versions) > 0 {
+		latest = raw.Versions[len(raw.Versions)-1].Version
+	}
+
+	return model.Paper{
+		ID:            "http://arxiv.org/abs/" + raw.ID + latest,
+		Title:         collapseSpace(raw.Title),
+		Authors:       AuthorNames(authors),
+		AuthorDetails: authors,
+		Venue:         venue,
+		Year:          year,
+		Abstract:      collapseSpace(raw.Abstract),
+		URL:           "https://arxiv.org/abs/" + raw.ID,
+		Source:        "arxiv",
+		Categories:    strings.Fields(raw.Categories),
+		PublishedAt:   published,
+		CCFClass:      GetCCFClass(venue),
+		DOI:           NormalizeDOI(raw.DOI),
+		ArxivID:       raw.ID,
+		Comment:       collapseSpace(raw.Comments),
+		Versions:      versions,
+		Institutions:  institutions,
+	}
+}
+
+// splitArxivAuthors 拆分 arXivRaw 的作者字符串，例如 "A. Smith (MIT), B. Jones and C. Lee"，
+// 作者后括号内的机构记入该作者的 Affiliations。带编号的写法 "A (1), B (1 and 2) ((1) MIT, (2) CMU)" 中，
+// 作者后的编号对应末尾编号列表中的机构
+func splitArxivAuthors(s string) []model.Author {
+	type rawAuthor struct {
+		name   string
+		groups []string // 作者后各个括号内的内容
+	}
+	var raws []rawAuthor
+	var pending []string
+	var cur, paren strings.Builder
+	depth := 0
+	flush := func() {
+		name := strings.TrimSpace(cur.String())
+		cur.Reset()
+		switch {
+		case name != "":
+			raws = append(raws, rawAuthor{name: name, groups: pending})
+		case len(raws) > 0:
+			raws[len(raws)-1].groups = append(raws[len(raws)-1].groups, pending...)
+		}
+		pending = nil
+	}
+	s = collapseSpace(s)
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '(':
+			if depth > 0 {
+				paren.WriteByte(s[i])
+			}
+			depth++
+		case s[i] == ')':
+			if depth == 0 {
+				continue
+			}
+			depth--
+			if depth > 0 {
+				paren.WriteByte(s[i])
+				continue
+			}
+			if g := strings.TrimSpace(paren.String()); g != "" {
+				pending = append(pending, g)
+			}
+			paren.Reset()
+		case depth > 0:
+			paren.WriteByte(s[i])
+		case s[i] == ',':
+			flush()
+		case strings.HasPrefix(s[i:], " and "):
+			flush()
+			i += len(" and ") - 1
+		default:
+			cur.WriteByte(s[i])
+		}
+	}
+	flush()
+
+	// 末尾的编号列表："(1) MIT, (2) CMU"
+	numbered := make(map[string]string)
+	for _, r := range raws {
+		for _, g := range r.groups {
+			if strings.HasPrefix(g, "(") {
+				for _, m := range numberedAffiliation.FindAllStringSubmatch(g, -1) {
+					if name := strings.Trim(strings.TrimSpace(m[2]), ",;"); name != "" {
+						numbered[m[1]] = strings.TrimSpace(name)
+					}
+				}
+			}
+		}
+	}
+
+	authors := make([]model.Author, 0, len(raws))
+	for _, r := range raws {
+		author := model.Author{Name: r.name}
+		for _, g := range r.groups {
+			switch {
+			case strings.HasPrefix(g, "("):
+				// 编号列表本身
+			case affiliationNumbers.MatchString(g):
+				for _, n := range strings.FieldsFunc(g, func(c rune) bool { return c < '0' || c > '9' }) {
+					if name, ok := numbered[n]; ok {
+						author.Affiliations = AppendInstitution(author.Affiliations, model.Institution{Name: name})
+					}
+				}
+			default:
+				author.Affiliations = AppendInstitution(author.Affiliations, model.Institution{Name: g})
+			}
+		}
+		authors = append(authors, author)
+	}
+	return authors
+}
+
+var (
+	affiliationNumbers  = regexp.MustCompile(`^(\d+|,|\s|and)+$`)
+	numberedAffiliation = regexp.MustCompile(`\((\d+)\)([^()]*)`)
+)
+
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,174 @@
+package provider
+
+import (
+	"strings"
+	"unicode"
+
+	"paper-scraper/internal/model"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// NormalizeORCID 去掉 ORCID 的 URL 前缀，例如 https://orcid.org/0000-0002-1825-0097 -> 0000-0002-1825-0097
+func NormalizeORCID(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.ToUpper(s)
+}
+
+// NormalizeOpenAlexID 去掉 OpenAlex ID 的 URL 前缀，例如 https://openalex.org/A5023888391 -> A5023888391
+func NormalizeOpenAlexID(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndex(s, "/"); i >= 0 {
+		s = s[i+1:]
+	}
+	return strings.ToUpper(s)
+}
+
+// AuthorNames 返回作者姓名列表（即 JSON 中扁平的 authors 字段）
+func AuthorNames(authors []model.Author) []string {
+	names := make([]string, 0, len(authors))
+	for _, a := range authors {
+		names = append(names, a.Name)
+	}
+	return names
+}
+
+// AuthorsOf 返回论文的结构化作者；没有结构化信息（如旧记录）时按姓名列表构造
+func AuthorsOf(p model.Paper) []model.Author {
+	if len(p.AuthorDetails) == len(p.Authors) && len(p.AuthorDetails) > 0 {
+		return p.AuthorDetails
+	}
+	authors := make([]model.Author, 0, len(p.Authors))
+	for _, name := range p.Authors {
+		authors = append(authors, model.Author{Name: name})
+	}
+	return authors
+}
+
+// AuthorNameKey 规范化作者姓名：去掉重音符号与标点，统一大小写，"Last, First" 调整为 "first last"
+func AuthorNameKey(name string) string {
+	if last, first, ok := strings.Cut(name, ","); ok {
+		name = first + " " + last
+	}
+	var b strings.Builder
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// 重音符号
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			b.WriteByte(' ')
+		}
+	}
+	return strings.Join(strings.Fields(b.String()), " ")
+}
+
+// authorInitialKey 返回“名的首字母 + 姓”，用于匹配 "J. Smith" 与 "John Smith"
+func authorInitialKey(name string) string {
+	fields := strings.Fields(AuthorNameKey(name))
+	if len(fields) < 2 {
+		return strings.Join(fields, " ")
+	}
+	return string([]rune(fields[0])[:1]) + " " + fields[len(fields)-1]
+}
+
+// CompareAuthorIDs 比较两位作者的标识符：任一标识符相同为同一人（match），
+// 同类标识符不同则一定不是同一人（conflict）
+func CompareAuthorIDs(a, b model.Author) (match, conflict bool) {
+	for _, pair := range [][2]string{
+		{a.ORCID, b.ORCID},
+		{a.OpenAlexID, b.OpenAlexID},
+		{a.S2ID, b.S2ID},
+		{a.DBLPID, b.DBLPID},
+	} {
+		if pair[0] == "" || pair[1] == "" {
+			continue
+		}
+		if pair[0] == pair[1] {
+			match = true
+		} else {
+			conflict = true
+		}
+	}
+	return match && !conflict, conflict
+}
+
+// FindAuthor 返回 a 在 list 中的位置，没有找到时返回 -1。匹配规则同 ReconcileAuthors
+func FindAuthor(list []model.Author, a model.Author) int {
+	return findAuthor(list, make([]bool, len(list)), a)
+}
+
+// findAuthor 在 list 中未被占用的作者里查找 o：先按标识符匹配，再按规范化的全名匹配，
+// 最后按“名的首字母 + 姓”唯一匹配；标识符冲突的作者不参与姓名匹配
+func findAuthor(list []model.Author, used []bool, o model.Author) int {
+	for i := range list {
+		if match, _ := CompareAuthorIDs(list[i], o); match && !used[i] {
+			return i
+		}
+	}
+	key := AuthorNameKey(o.Name)
+	for i := range list {
+		if _, conflict := CompareAuthorIDs(list[i], o); !used[i] && !conflict && key != "" && AuthorNameKey(list[i].Name) == key {
+			return i
+		}
+	}
+	initial, found := authorInitialKey(o.Name), -1
+	for i := range list {
+		if _, conflict := CompareAuthorIDs(list[i], o); !used[i] && !conflict && initial != "" && authorInitialKey(list[i].Name) == initial {
+			if found >= 0 {
+				return -1 // 不唯一
+			}
+			found = i
+		}
+	}
+	return found
+}
+
+// MergeAuthor 用 o 补全 a 缺失的标识符与所属机构（姓名以 a 为准），不修改 a 的机构列表
+func MergeAuthor(a, o model.Author) model.Author {
+	if a.Name == "" {
+		a.Name = o.Name
+	}
+	if a.ORCID == "" {
+		a.ORCID = o.ORCID
+	}
+	if a.OpenAlexID == "" {
+		a.OpenAlexID = o.OpenAlexID
+	}
+	if a.S2ID == "" {
+		a.S2ID = o.S2ID
+	}
+	if a.DBLPID == "" {
+		a.DBLPID = o.DBLPID
+	}
+	if len(o.Affiliations) > 0 {
+		affiliations := append([]model.Institution(nil), a.Affiliations...)
+		for _, inst := range o.Affiliations {
+			affiliations = AppendInstitution(affiliations, inst)
+		}
+		a.Affiliations = affiliations
+	}
+	return a
+}
+
+// ReconcileAuthors 将 other 中的作者信息合并到 base：先按标识符（ORCID、OpenAlex、S2、DBLP）匹配，
+// 再按规范化的全名匹配，最后按“名的首字母 + 姓”唯一匹配。匹配到的作者补全缺失的标识符与所属机构；
+// 未匹配到的作者不追加，以 base 的作者顺序与人数为准。返回新的列表，不修改输入
+func ReconcileAuthors(base, other []model.Author) []model.Author {
+	out := make([]model.Author, len(base))
+	copy(out, base)
+	used := make([]bool, len(out))
+	for _, o := range other {
+		i := findAuthor(out, used, o)
+		if i < 0 {
+			continue
+		}
+		used[i] = true
+		out[i] = MergeAuthor(out[i], o)
+	}
+	return out
+}
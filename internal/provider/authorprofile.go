@@ -0,0 +1,297 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"paper-scraper/internal/model"
+)
+
+// ErrNotFound 表示上游数据源中不存在请求的作者或论文
+var ErrNotFound = errors.New("not found")
+
+// 列表接口每页的条数上限：OpenAlex 为 200，S2 为 1000
+const (
+	openAlexMaxPerPage = 200
+	s2MaxPerPage       = 1000
+)
+
+// 向 S2 请求的作者字段
+const s2AuthorFields = "name,affiliations,paperCount,citationCount,hIndex,externalIds"
+
+// AuthorInfo 是数据源返回的作者档案，计数为数据源自身的统计
+type AuthorInfo struct {
+	Author     model.Author `json:"author"`
+	Source     string       `json:"source"`
+	WorksCount int          `json:"works_count"`
+	Citations  int          `json:"citations"`
+	HIndex     int          `json:"h_index"`
+}
+
+var (
+	openAlexAuthorIDPattern = regexp.MustCompile(`(?i)^A\d+$`)
+	orcidPattern            = regexp.MustCompile(`(?i)^\d{4}-\d{4}-\d{4}-\d{3}[\dX]$`)
+)
+
+// IsOpenAlexAuthorID 判断 s 是否为 OpenAlex 作者 ID（如 A5023888391）
+func IsOpenAlexAuthorID(s string) bool {
+	return openAlexAuthorIDPattern.MatchString(s)
+}
+
+// IsORCID 判断 s 是否为 ORCID（如 0000-0002-1825-0097）
+func IsORCID(s string) bool {
+	return orcidPattern.MatchString(s)
+}
+
+// --- OpenAlex ---
+
+// SearchOpenAlexAuthors 按姓名检索 OpenAlex 作者，按相关度排序
+func SearchOpenAlexAuthors(ctx context.Context, name string, limit int) ([]AuthorInfo, error) {
+	params := url.Values{}
+	params.Set("search", name)
+	params.Set("per-page", strconv.Itoa(limit))
+
+	resp, err := httpGet(ctx, "https://api.openalex.org/authors?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	var oaResp model.OAAuthorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&oaResp); err != nil {
+		return nil, err
+	}
+	authors := make([]AuthorInfo, 0, len(oaResp.Results))
+	for i := range oaResp.Results {
+		authors = append(authors, openAlexToAuthor(&oaResp.Results[i]))
+	}
+	return authors, nil
+}
+
+// FetchOpenAlexAuthor 查询 OpenAlex 作者，id 为 OpenAlex ID（A5023888391）或 ORCID
+func FetchOpenAlexAuthor(ctx context.Context, id string) (AuthorInfo, error) {
+	if IsORCID(id) {
+		id = "orcid:" + id
+	}
+	resp, err := httpGet(ctx, "https://api.openalex.org/authors/"+url.PathEscape(id), nil)
+	if err != nil {
+		return AuthorInfo{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return AuthorInfo{}, ErrNotFound
+	}
+	if resp.StatusCode != 200 {
+		return AuthorInfo{}, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	var author model.OAAuthor
+	if err := json.NewDecoder(resp.Body).Decode(&author); err != nil {
+		return AuthorInfo{}, err
+	}
+	return openAlexToAuthor(&author), nil
+}
+
+func openAlexToAuthor(a *model.OAAuthor) AuthorInfo {
+	author := model.Author{
+		Name:       a.DisplayName,
+		OpenAlexID: NormalizeOpenAlexID(a.ID),
+		ORCID:      NormalizeORCID(a.ORCID),
+	}
+	for _, inst := range a.LastKnownInstitutions {
+		author.Affiliations = AppendInstitution(author.Affiliations, model.Institution{
+			Name:    inst.DisplayName,
+			ROR:     inst.ROR,
+			Country: inst.CountryCode,
+		})
+	}
+	return AuthorInfo{
+		Author:     author,
+		Source:     "openalex",
+		WorksCount: a.WorksCount,
+		Citations:  a.CitedByCount,
+		HIndex:     a.SummaryStats.HIndex,
+	}
+}
+
+// FetchOpenAlexAuthorWorks 返回作者的论文（不限学科），按发布日期从新到旧，最多 limit 篇
+func FetchOpenAlexAuthorWorks(ctx context.Context, id string, limit int) ([]model.Paper, error) {
+	params := url.Values{}
+	params.Set("filter", "author.id:"+id)
+	params.Set("sort", "publication_date:desc")
+	return fetchOpenAlexAll(ctx, params, limit)
+}
+
+// FetchOpenAlexWorksByDOI 按 DOI 批量查询 OpenAlex 论文，未收录的 DOI 不返回
+func FetchOpenAlexWorksByDOI(ctx context.Context, dois []string) ([]model.Paper, error) {
+	return fetchOpenAlexWorksBy(ctx, "doi", dois)
+}
+
+// fetchOpenAlexWorksBy 按 field（doi、openalex 等）的取值批量查询 OpenAlex 论文
+func fetchOpenAlexWorksBy(ctx context.Context, field string, values []string) ([]model.Paper, error) {
+	var papers []model.Paper
+	// OpenAlex 的 OR 过滤单次最多 50 个值
+	for start := 0; start < len(values); start += 50 {
+		end := min(start+50, len(values))
+		params := url.Values{}
+		params.Set("filter", field+":"+strings.Join(values[start:end], "|"))
+		batch, err := fetchOpenAlexAll(ctx, params, end-start)
+		if err != nil {
+			return papers, err
+		}
+		papers = append(papers, batch...)
+	}
+	return papers, nil
+}
+
+// fetchOpenAlexAll 以游标分页读取 /works，直到取满 limit 篇或没有更多结果
+func fetchOpenAlexAll(ctx context.Context, params url.Values, limit int) ([]model.Paper, error) {
+	var papers []model.Paper
+	cursor := "*"
+	for cursor != "" && len(papers) < limit {
+		params.Set("per-page", strconv.Itoa(min(limit-len(papers), openAlexMaxPerPage)))
+		params.Set("cursor", cursor)
+		page, next, err := fetchOpenAlexWorks(ctx, params)
+		if err != nil {
+			return papers, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		papers = append(papers, page...)
+		cursor = next
+	}
+	if len(papers) > limit {
+		papers = papers[:limit]
+	}
+	return papers, nil
+}
+
+// --- Semantic Scholar ---
+
+// S2AuthorRecord 是 S2 /author 接口返回的作者
+type S2AuthorRecord struct {
+	AuthorID      string        `json:"authorId"`
+	Name          string        `json:"name"`
+	Affiliations  []string      `json:"affiliations,omitempty"`
+	PaperCount    int           `json:"paperCount"`
+	CitationCount int           `json:"citationCount"`
+	HIndex        int           `json:"hIndex"`
+	ExternalIDs   S2ExternalIDs `json:"externalIds,omitempty"`
+}
+
+// S2AuthorSearchResponse 是 /author/search 的响应
+type S2AuthorSearchResponse struct {
+	Total  int              `json:"total"`
+	Offset int              `json:"offset"`
+	Next   int              `json:"next,omitempty"`
+	Data   []S2AuthorRecord `json:"data"`
+}
+
+// S2AuthorPapersResponse 是 /author/{id}/papers 的响应
+type S2AuthorPapersResponse struct {
+	Offset int       `json:"offset"`
+	Next   int       `json:"next,omitempty"`
+	Data   []S2Paper `json:"data"`
+}
+
+// SearchS2Authors 按姓名检索 S2 作者
+func SearchS2Authors(ctx context.Context, name string, limit int) ([]AuthorInfo, error) {
+	params := url.Values{}
+	params.Set("query", name)
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("fields", s2AuthorFields)
+
+	var s2Resp S2AuthorSearchResponse
+	if err := getS2JSON(ctx, "/author/search?"+params.Encode(), &s2Resp); err != nil {
+		return nil, err
+	}
+	authors := make([]AuthorInfo, 0, len(s2Resp.Data))
+	for i := range s2Resp.Data {
+		authors = append(authors, s2ToAuthor(&s2Resp.Data[i]))
+	}
+	return authors, nil
+}
+
+// FetchS2Author 按 authorId 查询 S2 作者
+func FetchS2Author(ctx context.Context, id string) (AuthorInfo, error) {
+	var author S2AuthorRecord
+	if err := getS2JSON(ctx, "/author/"+url.PathEscape(id)+"?fields="+s2AuthorFields, &author); err != nil {
+		return AuthorInfo{}, err
+	}
+	return s2ToAuthor(&author), nil
+}
+
+// FetchS2AuthorPapers 返回 S2 作者的论文，最多 limit 篇（S2 按发布时间从新到旧返回）
+func FetchS2AuthorPapers(ctx context.Context, id string, limit int) ([]model.Paper, error) {
+	var papers []model.Paper
+	offset := 0
+	for len(papers) < limit {
+		params := url.Values{}
+		params.Set("fields", s2SearchFields)
+		params.Set("offset", strconv.Itoa(offset))
+		params.Set("limit", strconv.Itoa(min(limit-len(papers), s2MaxPerPage)))
+
+		var s2Resp S2AuthorPapersResponse
+		if err := getS2JSON(ctx, "/author/"+url.PathEscape(id)+"/papers?"+params.Encode(), &s2Resp); err != nil {
+			return papers, err
+		}
+		for i := range s2Resp.Data {
+			papers = append(papers, s2ToPaper(&s2Resp.Data[i]))
+		}
+		if s2Resp.Next <= offset || len(s2Resp.Data) == 0 {
+			break
+		}
+		offset = s2Resp.Next
+	}
+	return papers, nil
+}
+
+// getS2JSON 请求 S2 接口并解码 JSON 响应，404 返回 ErrNotFound
+func getS2JSON(ctx context.Context, path string, v any) error {
+	endpoint, header := s2Endpoint(path)
+	resp, err := httpGet(ctx, endpoint, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ErrNotFound
+	}
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("S2 status %d", resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+func s2ToAuthor(a *S2AuthorRecord) AuthorInfo {
+	// S2 的 DBLP 外部 ID 是 DBLP 上的姓名而非 pid，不用于匹配
+	author := model.Author{
+		Name:  a.Name,
+		S2ID:  a.AuthorID,
+		ORCID: NormalizeORCID(a.ExternalIDs["ORCID"]),
+	}
+	for _, name := range a.Affiliations {
+		author.Affiliations = AppendInstitution(author.Affiliations, model.Institution{Name: name})
+	}
+	return AuthorInfo{
+		Author:     author,
+		Source:     "s2",
+		WorksCount: a.PaperCount,
+		Citations:  a.CitationCount,
+		HIndex:     a.HIndex,
+	}
+}
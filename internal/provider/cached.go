@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"paper-scraper/internal/cache"
+	"paper-scraper/internal/model"
+)
+
+// cachedProvider 为上游数据源加一层响应缓存。命中未过期缓存时不访问上游；
+// 上游失败时若有过期缓存则返回旧数据并标记为 stale
+type cachedProvider struct {
+	Provider
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+// WithCache 用缓存包装数据源，ttl 为该数据源的缓存有效期
+func WithCache(p Provider, c *cache.Cache, ttl time.Duration) Provider {
+	if c == nil || ttl <= 0 {
+		return p
+	}
+	return &cachedProvider{Provider: p, cache: c, ttl: ttl}
+}
+
+func (p *cachedProvider) Search(ctx context.Context, q Query) ([]model.Paper, error) {
+	key := CacheKey(p.Name(), q)
+	value, fresh, ok := p.cache.Get(key)
+	var cached []model.Paper
+	if ok {
+		if err := json.Unmarshal(value, &cached); err != nil {
+			ok = false
+		}
+	}
+	if ok && fresh {
+		cache.Record(ctx, cache.StatusHit)
+		return cached, nil
+	}
+
+	papers, err := p.Provider.Search(ctx, q)
+	if err != nil {
+		if ok {
+			fmt.Printf("%s error, serving stale cache: %v\n", p.Name(), err)
+			cache.Record(ctx, cache.StatusStale)
+			return cached, nil
+		}
+		cache.Record(ctx, cache.StatusMiss)
+		return nil, err
+	}
+	cache.Record(ctx, cache.StatusMiss)
+	if raw, err := json.Marshal(papers); err == nil {
+		p.cache.Set(key, raw, p.ttl)
+	}
+	return papers, nil
+}
+
+// SearchPage 缓存分页结果，键中包含分页位置
+func (p *cachedProvider) SearchPage(ctx context.Context, q Query, cursor string) (Page, error) {
+	key := cache.Key(CacheKey(p.Name(), q), "page", cursor)
+	value, fresh, ok := p.cache.Get(key)
+	var cached Page
+	if ok {
+		if err := json.Unmarshal(value, &cached); err != nil {
+			ok = false
+		}
+	}
+	if ok && fresh {
+		cache.Record(ctx, cache.StatusHit)
+		return cached, nil
+	}
+
+	page, err := SearchPage(ctx, p.Provider, q, cursor)
+	if err != nil {
+		if ok {
+			fmt.Printf("%s error, serving stale cache: %v\n", p.Name(), err)
+			cache.Record(ctx, cache.StatusStale)
+			return cached, nil
+		}
+		cache.Record(ctx, cache.StatusMiss)
+		return Page{}, err
+	}
+	cache.Record(ctx, cache.StatusMiss)
+	if raw, err := json.Marshal(page); err == nil {
+		p.cache.Set(key, raw, p.ttl)
+	}
+	return page, nil
+}
+
+// CacheKey 由数据源名称与规范化后的查询参数生成缓存键，
+// 检索词大小写与多余空白不影响命中
+func CacheKey(source string, q Query) string {
+	expr := ""
+	if q.Expr != nil {
+		expr = q.Expr.String()
+	}
+	return cache.Key(
+		source,
+		strings.Join(strings.Fields(strings.ToLower(q.Text)), " "),
+		strings.ToLower(expr),
+		strconv.Itoa(q.Limit),
+		strconv.Itoa(q.Offset),
+		q.StartDate,
+		q.EndDate,
+		q.Sort,
+		strings.Join(q.Categories, ","),
+		strings.Join(q.Concepts, ","),
+	)
+}
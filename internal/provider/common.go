@@ -107,6 +107,61 @@ func GetCCFClass(venue string) string {
 	return "None"
 }
 
+// IsPlaceholderVenue 判断 venue 是否只是数据源名称等占位值（如 "arXiv"、"CoRR"），而非真实的期刊/会议
+func IsPlaceholderVenue(venue string) bool {
+	v := strings.ToLower(strings.TrimSpace(venue))
+	return v == "" || v == "openalex" || v == "dblp" || v == "corr" || v == "semantic scholar" ||
+		strings.Contains(v, "arxiv")
+}
+
+// NormalizeDOI 去除 DOI 的 URL 前缀并统一为小写，例如
+// "https://doi.org/10.1109/CVPR.2023.001" -> "10.1109/cvpr.2023.001"
+func NormalizeDOI(doi string) string {
+	doi = strings.TrimSpace(doi)
+	for _, prefix := range []string{"https://doi.org/", "http://doi.org/", "https://dx.doi.org/", "http://dx.doi.org/", "doi:"} {
+		if len(doi) >= len(prefix) && strings.EqualFold(doi[:len(prefix)], prefix) {
+			doi = doi[len(prefix):]
+			break
+		}
+	}
+	return strings.ToLower(doi)
+}
+
+// ExtractArxivID 从 arXiv 链接、DOI（10.48550/arXiv.xxxx）或带版本号的 ID 中提取不带版本的 arXiv ID，
+// 例如 "http://arxiv.org/abs/2106.12345v1" -> "2106.12345"。无法识别时返回空字符串
+func ExtractArxivID(raw string) string {
+	id := strings.TrimSpace(raw)
+	lower := strings.ToLower(id)
+	switch {
+	case strings.Contains(lower, "arxiv.org/abs/"):
+		id = id[strings.Index(lower, "arxiv.org/abs/")+len("arxiv.org/abs/"):]
+	case strings.Contains(lower, "arxiv.org/pdf/"):
+		id = id[strings.Index(lower, "arxiv.org/pdf/")+len("arxiv.org/pdf/"):]
+		id = strings.TrimSuffix(id, ".pdf")
+	case strings.HasPrefix(NormalizeDOI(lower), "10.48550/arxiv."):
+		id = NormalizeDOI(lower)[len("10.48550/arxiv."):]
+	case strings.HasPrefix(lower, "arxiv:"):
+		id = id[len("arxiv:"):]
+	default:
+		return ""
+	}
+	id = strings.Trim(id, "/ ")
+	// 移除版本后缀（例如 v1, v2）
+	if idx := strings.LastIndex(id, "v"); idx > 0 && idx < len(id)-1 {
+		isVersion := true
+		for _, r := range id[idx+1:] {
+			if r < '0' || r > '9' {
+				isVersion = false
+				break
+			}
+		}
+		if isVersion {
+			id = id[:idx]
+		}
+	}
+	return id
+}
+
 func parseOpenAlexAbstract(inverted map[string][]int) string {
 	if len(inverted) == 0 {
 		return ""
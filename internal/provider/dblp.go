@@ -0,0 +1,150 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/query"
+)
+
+type dblpProvider struct{}
+
+func (dblpProvider) Name() string { return "dblp" }
+
+func (dblpProvider) Capabilities() Capabilities {
+	// DBLP 只按相关度排序，日期仅能精确到年份
+	return Capabilities{Offset: true}
+}
+
+func (d dblpProvider) Search(ctx context.Context, q Query) ([]model.Paper, error) {
+	page, err := d.SearchPage(ctx, q, strconv.Itoa(q.Offset))
+	return page.Papers, err
+}
+
+// SearchPage 以 DBLP 的 f（首条结果下标）作为分页位置
+func (dblpProvider) SearchPage(ctx context.Context, q Query, cursor string) (Page, error) {
+	offset, _ := strconv.Atoi(cursor)
+	papers, err := FetchDBLP(ctx, q.Text, q.Limit, offset, q.StartDate, q.EndDate)
+	if err != nil {
+		return Page{}, err
+	}
+	// DBLP 只支持关键词检索，字段、年份与否定条件均在结果上后置过滤
+	return Page{
+		Papers: query.Filter(q.Expr, papers),
+		Next:   nextOffset(offset, len(papers), q.Limit),
+	}, nil
+}
+
+func FetchDBLP(ctx context.Context, query string, limit, offset int, startDate, endDate string) ([]model.Paper, error) {
+	if strings.TrimSpace(query) == "" {
+		// DBLP 不支持空查询
+		return nil, nil
+	}
+
+	searchQuery := query
+	// DBLP 的 year 分面只能精确到年份，跨年时不加限制，交由调用方做严格日期过滤
+	if len(startDate) >= 4 && len(endDate) >= 4 && startDate[:4] == endDate[:4] {
+		searchQuery = fmt.Sprintf("%s year:%s:", query, startDate[:4])
+	}
+
+	params := url.Values{}
+	params.Set("q", searchQuery)
+	params.Set("format", "json")
+	params.Set("h", strconv.Itoa(limit))
+	params.Set("f", strconv.Itoa(offset))
+
+	resp, err := httpGet(ctx, "https://dblp.org/search/publ/api?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	var dblpResp model.DBLPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dblpResp); err != nil {
+		return nil, err
+	}
+
+	var papers []model.Paper
+	for _, hit := range dblpResp.Result.Hits.Hit {
+		papers = append(papers, dblpToPaper(hit.Info))
+	}
+	return papers, nil
+}
+
+func dblpToPaper(info model.DBLPInfo) model.Paper {
+	var authors []model.Author
+	for _, a := range info.Authors.Author {
+		authors = append(authors, model.Author{Name: cleanDBLPName(a.Text), DBLPID: a.PID})
+	}
+
+	venue := strings.Join(info.Venue, ", ")
+	if venue == "" {
+		venue = "DBLP"
+	}
+
+	var yearPtr *int
+	if y, err := strconv.Atoi(info.Year); err == nil {
+		yearPtr = &y
+	}
+
+	paper := model.Paper{
+		ID:            info.URL,
+		Title:         strings.TrimSuffix(strings.TrimSpace(info.Title), "."),
+		Authors:       AuthorNames(authors),
+		AuthorDetails: authors,
+		Venue:         venue,
+		Year:          yearPtr,
+		URL:           info.URL,
+		Source:        "dblp",
+		Categories:    []string{info.Type},
+		PublishedAt:   info.Year,
+		CCFClass:      dblpCCFClass(info.Key, venue),
+		DOI:           NormalizeDOI(info.DOI),
+	}
+	if len(info.EE) > 0 {
+		paper.URL = info.EE[0]
+	}
+	// CoRR 条目的电子版链接指向 arXiv
+	for _, ee := range info.EE {
+		if id := ExtractArxivID(ee); id != "" {
+			paper.ArxivID = id
+			break
+		}
+	}
+	if paper.ID == "" {
+		paper.ID = "https://dblp.org/rec/" + info.Key
+	}
+	return paper
+}
+
+// dblpCCFClass 优先使用 DBLP 记录键中的规范会议/期刊标识（如 conf/cvpr、journals/pami）
+// 匹配 CCF 目录，找不到时再回退到对 venue 文本的模糊匹配
+func dblpCCFClass(key, venue string) string {
+	parts := strings.Split(key, "/")
+	if len(parts) >= 2 && (parts[0] == "conf" || parts[0] == "journals") {
+		if class, ok := CCFCatalog[parts[1]]; ok {
+			return class
+		}
+	}
+	return GetCCFClass(venue)
+}
+
+// cleanDBLPName 去掉 DBLP 用于区分同名作者的数字后缀，例如 "Wei Zhang 0001"
+func cleanDBLPName(name string) string {
+	fields := strings.Fields(name)
+	if len(fields) > 1 {
+		if _, err := strconv.Atoi(fields[len(fields)-1]); err == nil {
+			fields = fields[:len(fields)-1]
+		}
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,285 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ClientConfig 是所有数据源共用的 HTTP 客户端配置
+type ClientConfig struct {
+	UserAgent  string        // 请求的 User-Agent
+	Mailto     string        // 联系邮箱：附加到 User-Agent，并作为 OpenAlex 的 mailto 参数进入 polite pool
+	Timeout    time.Duration // 单次请求超时
+	MaxRetries int           // 429 / 5xx / 网络错误时的最大重试次数
+	BaseDelay  time.Duration // 指数退避的初始间隔
+	MaxDelay   time.Duration // 单次等待的上限
+}
+
+var (
+	clientMu     sync.RWMutex
+	clientConfig = ClientConfig{
+		UserAgent:  "ScholarX/1.0",
+		Timeout:    15 * time.Second,
+		MaxRetries: 3,
+		BaseDelay:  time.Second,
+		MaxDelay:   30 * time.Second,
+	}
+	httpClient = &http.Client{Timeout: 15 * time.Second}
+)
+
+// Configure 设置共用 HTTP 客户端，零值字段保留默认配置
+func Configure(cfg ClientConfig) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	if cfg.UserAgent != "" {
+		clientConfig.UserAgent = cfg.UserAgent
+	}
+	if cfg.Mailto != "" {
+		clientConfig.Mailto = cfg.Mailto
+	}
+	if cfg.Timeout > 0 {
+		clientConfig.Timeout = cfg.Timeout
+		httpClient = &http.Client{Timeout: cfg.Timeout}
+	}
+	if cfg.MaxRetries > 0 {
+		clientConfig.MaxRetries = cfg.MaxRetries
+	}
+	if cfg.BaseDelay > 0 {
+		clientConfig.BaseDelay = cfg.BaseDelay
+	}
+	if cfg.MaxDelay > 0 {
+		clientConfig.MaxDelay = cfg.MaxDelay
+	}
+}
+
+func currentClient() (*http.Client, ClientConfig) {
+	clientMu.RLock()
+	defer clientMu.RUnlock()
+	return httpClient, clientConfig
+}
+
+// --- 每个主机的令牌桶限流 ---
+
+// RateLimit 表示每 Every 补充一个令牌，最多积攒 Burst 个
+type RateLimit struct {
+	Every time.Duration
+	Burst int
+}
+
+// 各上游 API 的访问频率要求：arXiv 要求每 3 秒不超过 1 次请求，
+// Semantic Scholar 未授权时约每秒 1 次
+var hostLimits = map[string]RateLimit{
+	"export.arxiv.org":        {Every: 3 * time.Second, Burst: 1},
+	"oaipmh.arxiv.org":        {Every: 3 * time.Second, Burst: 1},
+	"api.openalex.org":        {Every: 100 * time.Millisecond, Burst: 10},
+	"api.semanticscholar.org": {Every: time.Second, Burst: 1},
+	"dblp.org":                {Every: time.Second, Burst: 2},
+}
+
+type tokenBucket struct {
+	mu     sync.Mutex
+	limit  RateLimit
+	tokens float64
+	last   time.Time
+}
+
+var (
+	bucketsMu sync.Mutex
+	buckets   = make(map[string]*tokenBucket)
+)
+
+// SetRateLimit 设置（或覆盖）某个主机的限流规则
+func SetRateLimit(host string, limit RateLimit) {
+	bucketsMu.Lock()
+	defer bucketsMu.Unlock()
+	hostLimits[host] = limit
+	delete(buckets, host)
+}
+
+func bucketFor(host string) *tokenBucket {
+	bucketsMu.Lock()
+	defer bucketsMu.Unlock()
+	if b, ok := buckets[host]; ok {
+		return b
+	}
+	limit, ok := hostLimits[host]
+	if !ok {
+		return nil
+	}
+	if limit.Burst <= 0 {
+		limit.Burst = 1
+	}
+	b := &tokenBucket{limit: limit, tokens: float64(limit.Burst), last: time.Now()}
+	buckets[host] = b
+	return b
+}
+
+// wait 取走一个令牌，令牌不足时预订下一个并等待到可用时刻；等待期间 ctx 取消时归还预订
+func (b *tokenBucket) wait(ctx context.Context) error {
+	b.mu.Lock()
+	now := time.Now()
+	b.tokens += float64(now.Sub(b.last)) / float64(b.limit.Every)
+	if max := float64(b.limit.Burst); b.tokens > max {
+		b.tokens = max
+	}
+	b.last = now
+	b.tokens--
+	var delay time.Duration
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens * float64(b.limit.Every))
+	}
+	b.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		// 请求已取消，归还预订的令牌，避免推迟后续请求
+		b.mu.Lock()
+		b.tokens++
+		b.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// --- 带限流与重试的请求 ---
+
+// httpGet 发送 GET 请求，header 可为 nil
+func httpGet(ctx context.Context, rawURL string, header http.Header) (*http.Response, error) {
+	return doRequest(ctx, http.MethodGet, rawURL, header, nil)
+}
+
+// httpPost 发送 JSON POST 请求，body 在重试时会重新发送
+func httpPost(ctx context.Context, rawURL string, header http.Header, body []byte) (*http.Response, error) {
+	h := header.Clone()
+	if h == nil {
+		h = http.Header{}
+	}
+	h.Set("Content-Type", "application/json")
+	return doRequest(ctx, http.MethodPost, rawURL, h, body)
+}
+
+// doRequest 按主机限流发送请求，遇到 429 / 5xx / 网络错误时以指数退避加随机抖动重试，
+// 并优先遵循 Retry-After。重试耗尽后返回最后一次的响应（或错误），由调用方检查状态码
+func doRequest(ctx context.Context, method, rawURL string, header http.Header, body []byte) (*http.Response, error) {
+	client, cfg := currentClient()
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	// OpenAlex polite pool：带 mailto 的请求享有更稳定的配额
+	if cfg.Mailto != "" && u.Host == "api.openalex.org" {
+		q := u.Query()
+		if q.Get("mailto") == "" {
+			q.Set("mailto", cfg.Mailto)
+			u.RawQuery = q.Encode()
+		}
+	}
+	userAgent := cfg.UserAgent
+	if cfg.Mailto != "" {
+		userAgent += " (mailto:" + cfg.Mailto + ")"
+	}
+	bucket := bucketFor(u.Host)
+
+	for attempt := 0; ; attempt++ {
+		if bucket != nil {
+			if err := bucket.wait(ctx); err != nil {
+				return nil, err
+			}
+		}
+
+		var reader io.Reader
+		if body != nil {
+			reader = bytes.NewReader(body)
+		}
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), reader)
+		if err != nil {
+			return nil, err
+		}
+		for k, v := range header {
+			req.Header[k] = v
+		}
+		req.Header.Set("User-Agent", userAgent)
+
+		resp, err := client.Do(req)
+		retryable := err != nil || resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		if ctx.Err() != nil {
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return nil, ctx.Err()
+		}
+		if !retryable || attempt >= cfg.MaxRetries {
+			return resp, err
+		}
+
+		delay := backoff(cfg, attempt)
+		if err != nil {
+			fmt.Printf("%s request error (attempt %d): %v\n", u.Host, attempt+1, err)
+		} else {
+			if ra, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+				delay = ra
+				if delay > cfg.MaxDelay {
+					delay = cfg.MaxDelay
+				}
+			}
+			fmt.Printf("%s status %d (attempt %d), retrying in %v\n", u.Host, resp.StatusCode, attempt+1, delay)
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// backoff 返回第 attempt 次重试前的等待时间：BaseDelay * 2^attempt，外加至多一半的随机抖动
+func backoff(cfg ClientConfig, attempt int) time.Duration {
+	d := cfg.BaseDelay << attempt
+	if d <= 0 || d > cfg.MaxDelay {
+		d = cfg.MaxDelay
+	}
+	jitter := time.Duration(rand.Int63n(int64(d)/2 + 1))
+	if d+jitter > cfg.MaxDelay {
+		return cfg.MaxDelay
+	}
+	return d + jitter
+}
+
+// retryAfter 解析 Retry-After 头，支持秒数与 HTTP 日期两种形式
+func retryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,31 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucketRefundsCanceledWait(t *testing.T) {
+	b := &tokenBucket{limit: RateLimit{Every: time.Hour, Burst: 1}, tokens: 1, last: time.Now()}
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatalf("first wait: %v", err)
+	}
+
+	// 令牌已用完，下一个要等一小时；取消的请求不应占用它
+	for i := 0; i < 3; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Millisecond)
+		err := b.wait(ctx)
+		cancel()
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("wait %d: err = %v, want deadline exceeded", i, err)
+		}
+	}
+	b.mu.Lock()
+	tokens := b.tokens
+	b.mu.Unlock()
+	if tokens < -0.01 {
+		t.Errorf("tokens = %.3f after canceled waits, want about 0", tokens)
+	}
+}
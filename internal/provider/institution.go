@@ -0,0 +1,42 @@
+package provider
+
+import (
+	"strings"
+
+	"paper-scraper/internal/model"
+)
+
+// InstitutionKey 返回机构的去重键：优先使用 ROR ID，否则使用规范化后的名称
+func InstitutionKey(inst model.Institution) string {
+	if inst.ROR != "" {
+		return "ror:" + strings.TrimPrefix(strings.ToLower(inst.ROR), "https://ror.org/")
+	}
+	return "name:" + strings.ToLower(collapseSpace(inst.Name))
+}
+
+// AppendInstitution 将机构追加到列表中；已存在同一机构（ROR ID 或名称相同）时只补全其缺失的字段
+func AppendInstitution(list []model.Institution, inst model.Institution) []model.Institution {
+	inst.Name = collapseSpace(inst.Name)
+	if inst.Name == "" && inst.ROR == "" {
+		return list
+	}
+	name := strings.ToLower(inst.Name)
+	for i, existing := range list {
+		sameROR := inst.ROR != "" && strings.EqualFold(existing.ROR, inst.ROR)
+		sameName := name != "" && strings.ToLower(existing.Name) == name
+		if !sameROR && !sameName {
+			continue
+		}
+		if existing.ROR == "" {
+			list[i].ROR = inst.ROR
+		}
+		if existing.Country == "" {
+			list[i].Country = inst.Country
+		}
+		if existing.Name == "" {
+			list[i].Name = inst.Name
+		}
+		return list
+	}
+	return append(list, inst)
+}
@@ -1,99 +1,301 @@
 package provider
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
-	"time"
 
 	"paper-scraper/internal/model"
+	"paper-scraper/internal/query"
 )
 
-func FetchOpenAlex(query string, limit, offset int, startDate, endDate string, sortOrder string) ([]model.Paper, error) {
+type openAlexProvider struct{}
+
+func (openAlexProvider) Name() string { return "openalex" }
+
+func (openAlexProvider) Capabilities() Capabilities {
+	return Capabilities{DateRange: true, Sort: true, Offset: true}
+}
+
+func (openAlexProvider) Search(ctx context.Context, q Query) ([]model.Paper, error) {
+	search, filters, residual := openAlexQueryParams(q)
+	papers, _, err := fetchOpenAlex(ctx, search, filters, q.Limit, q.Offset, "", q.StartDate, q.EndDate, q.Sort)
+	if err != nil {
+		return nil, err
+	}
+	return query.Filter(residual, papers), nil
+}
+
+// SearchPage 使用 OpenAlex 的游标分页（cursor=*），不受 page 参数 10000 条的深度限制
+func (openAlexProvider) SearchPage(ctx context.Context, q Query, cursor string) (Page, error) {
+	if cursor == "" {
+		cursor = "*"
+	}
+	search, filters, residual := openAlexQueryParams(q)
+	papers, next, err := fetchOpenAlex(ctx, search, filters, q.Limit, 0, cursor, q.StartDate, q.EndDate, q.Sort)
+	if err != nil {
+		return Page{}, err
+	}
+	if len(papers) == 0 {
+		next = ""
+	}
+	return Page{Papers: query.Filter(residual, papers), Next: next}, nil
+}
+
+// openAlexQueryParams 将 Query 翻译为 search 参数与过滤器，concept 限定以 | 表示满足其一
+func openAlexQueryParams(q Query) (search string, filters []string, residual query.Node) {
+	search = q.Text
+	if q.Expr != nil {
+		search, filters, residual = openAlexSearchParams(q.Expr)
+	}
+	if len(q.Concepts) > 0 {
+		filters = append(filters, "concepts.id:"+strings.Join(q.Concepts, "|"))
+	}
+	return search, filters, residual
+}
+
+func FetchOpenAlex(ctx context.Context, query string, limit, offset int, startDate, endDate string, sortOrder string) ([]model.Paper, error) {
+	papers, _, err := fetchOpenAlex(ctx, query, nil, limit, offset, "", startDate, endDate, sortOrder)
+	return papers, err
+}
+
+// fetchOpenAlex 以 search 作为全文检索参数，extraFilters 追加到默认的计算机科学过滤条件之后。
+// cursor 非空时使用游标分页（忽略 offset），并返回下一页的游标
+func fetchOpenAlex(ctx context.Context, search string, extraFilters []string, limit, offset int, cursor, startDate, endDate string, sortOrder string) ([]model.Paper, string, error) {
 	filters := []string{"concepts.id:C41008148"} // 计算机科学
 	if startDate != "" && endDate != "" {
 		filters = append(filters, fmt.Sprintf("from_publication_date:%s", startDate))
 		filters = append(filters, fmt.Sprintf("to_publication_date:%s", endDate))
 	}
-
-	// OpenAlex 使用从 1 开始的页码
-	page := (offset / limit) + 1
+	filters = append(filters, extraFilters...)
 
 	params := url.Values{}
 	params.Set("per-page", strconv.Itoa(limit))
-	params.Set("page", strconv.Itoa(page))
+	if cursor != "" {
+		params.Set("cursor", cursor)
+	} else {
+		// OpenAlex 使用从 1 开始的页码
+		page := (offset / limit) + 1
+		params.Set("page", strconv.Itoa(page))
+	}
 	params.Set("filter", strings.Join(filters, ","))
 
-	if sortOrder == "published_asc" {
+	switch {
+	case sortOrder == "published_asc":
 		params.Set("sort", "publication_date:asc")
-	} else {
+	case sortOrder == "citations":
+		params.Set("sort", "cited_by_count:desc")
+	case sortOrder == "relevance" && search != "":
+		// 相关度排序仅在带 search 参数时可用
+		params.Set("sort", "relevance_score:desc")
+	default:
 		params.Set("sort", "publication_date:desc")
 	}
 
-	if query != "" {
-		params.Set("search", query)
+	if search != "" {
+		params.Set("search", search)
 	}
 
-	client := http.Client{Timeout: 15 * time.Second}
-	resp, err := client.Get("https://api.openalex.org/works?" + params.Encode())
+	return fetchOpenAlexWorks(ctx, params)
+}
+
+// fetchOpenAlexWorks 请求 /works 接口并转换结果，返回下一页的游标（仅游标分页时非空）
+func fetchOpenAlexWorks(ctx context.Context, params url.Values) ([]model.Paper, string, error) {
+	resp, err := httpGet(ctx, "https://api.openalex.org/works?"+params.Encode(), nil)
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("status code %d", resp.StatusCode)
+		return nil, "", fmt.Errorf("status code %d", resp.StatusCode)
 	}
 
 	var oaResp model.OAResponse
 	if err := json.NewDecoder(resp.Body).Decode(&oaResp); err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	var papers []model.Paper
-	for _, item := range oaResp.Results {
-		var authors []string
-		for _, ship := range item.Authorships {
-			if ship.Author.DisplayName != "" {
-				authors = append(authors, ship.Author.DisplayName)
-			}
+	papers := make([]model.Paper, 0, len(oaResp.Results))
+	for i := range oaResp.Results {
+		papers = append(papers, openAlexToPaper(&oaResp.Results[i]))
+	}
+	nextCursor := ""
+	if oaResp.Meta.NextCursor != nil {
+		nextCursor = *oaResp.Meta.NextCursor
+	}
+	return papers, nextCursor, nil
+}
+
+// openAlexToPaper 将 OpenAlex work 转换为论文
+func openAlexToPaper(item *model.OAWork) model.Paper {
+	var authors []model.Author
+	var institutions []model.Institution
+	for _, ship := range item.Authorships {
+		author := model.Author{
+			Name:       ship.Author.DisplayName,
+			OpenAlexID: NormalizeOpenAlexID(ship.Author.ID),
+			ORCID:      NormalizeORCID(ship.Author.ORCID),
+		}
+		for _, inst := range ship.Institutions {
+			i := model.Institution{Name: inst.DisplayName, ROR: inst.ROR, Country: inst.CountryCode}
+			author.Affiliations = AppendInstitution(author.Affiliations, i)
+			institutions = AppendInstitution(institutions, i)
+		}
+		if author.Name != "" {
+			authors = append(authors, author)
+		}
+	}
+	var categories []string
+	for i, c := range item.Concepts {
+		if i >= 5 {
+			break
+		}
+		categories = append(categories, c.DisplayName)
+	}
+
+	venue := item.PrimaryLocation.Source.DisplayName
+	if venue == "" {
+		venue = "OpenAlex"
+	}
+
+	y := item.PublicationYear
+
+	paper := model.Paper{
+		ID:            item.ID,
+		Title:         item.DisplayName,
+		Authors:       AuthorNames(authors),
+		AuthorDetails: authors,
+		Venue:         venue,
+		Year:          &y,
+		Abstract:      parseOpenAlexAbstract(item.AbstractInverted),
+		URL:           item.PrimaryLocation.LandingPageURL,
+		Source:        "openalex",
+		Categories:    categories,
+		PublishedAt:   item.PublicationDate,
+		Citations:     item.CitedByCount,
+		CCFClass:      GetCCFClass(venue),
+		DOI:           NormalizeDOI(item.DOI),
+		Institutions:  institutions,
+	}
+	if paper.URL == "" {
+		paper.URL = item.ID
+	}
+	// arXiv 预印本在 OpenAlex 中通常带有 10.48550 DOI 或 arxiv.org 落地页
+	paper.ArxivID = ExtractArxivID(item.DOI)
+	for _, loc := range item.Locations {
+		if paper.ArxivID != "" {
+			break
 		}
-		var categories []string
-		for i, c := range item.Concepts {
-			if i >= 5 {
-				break
+		paper.ArxivID = ExtractArxivID(loc.LandingPageURL)
+	}
+	return paper
+}
+
+// OpenAlex 字段检索对应的过滤器
+var openAlexFieldFilters = map[string]string{
+	query.FieldTitle:    "title.search",
+	query.FieldAbstract: "abstract.search",
+	query.FieldAuthor:   "raw_author_name.search",
+}
+
+// openAlexSearchParams 将检索表达式翻译为 OpenAlex 参数：
+// 不限字段的部分翻译为支持布尔运算的 search 参数，顶层的字段条件与年份翻译为 filter，
+// 其余条件（如 venue、OR 中的字段条件）放入 residual 做后置过滤
+func openAlexSearchParams(expr query.Node) (search string, filters []string, residual query.Node) {
+	var freeText, rest []query.Node
+	for _, c := range query.Conjuncts(expr) {
+		switch v := c.(type) {
+		case *query.Term:
+			if v.Field == query.FieldAny {
+				freeText = append(freeText, c)
+				continue
+			}
+			if name, ok := openAlexFieldFilters[v.Field]; ok {
+				// 逗号用于分隔过滤器，不能出现在取值中
+				filters = append(filters, name+":"+strings.ReplaceAll(v.Value, ",", " "))
+				continue
+			}
+		case *query.YearRange:
+			switch {
+			case v.From != 0 && v.To != 0:
+				filters = append(filters, fmt.Sprintf("publication_year:%d-%d", v.From, v.To))
+			case v.From != 0:
+				filters = append(filters, fmt.Sprintf("publication_year:>%d", v.From-1))
+			default:
+				filters = append(filters, fmt.Sprintf("publication_year:<%d", v.To+1))
+			}
+			continue
+		default:
+			if query.IsFreeText(c) {
+				freeText = append(freeText, c)
+				continue
 			}
-			categories = append(categories, c.DisplayName)
 		}
+		rest = append(rest, c)
+	}
 
-		venue := item.PrimaryLocation.Source.DisplayName
-		if venue == "" {
-			venue = "OpenAlex"
+	if len(freeText) > 0 {
+		if s, ok := openAlexBoolean(query.AndOf(freeText)); ok {
+			search = s
+		} else {
+			rest = append(rest, freeText...)
 		}
+	}
+	return search, filters, query.AndOf(rest)
+}
 
-		y := item.PublicationYear
-
-		paper := model.Paper{
-			ID:          item.ID,
-			Title:       item.DisplayName,
-			Authors:     authors,
-			Venue:       venue,
-			Year:        &y,
-			Abstract:    parseOpenAlexAbstract(item.AbstractInverted),
-			URL:         item.PrimaryLocation.LandingPageURL,
-			Source:      "openalex",
-			Categories:  categories,
-			PublishedAt: item.PublicationDate,
-			Citations:   item.CitedByCount,
-			CCFClass:    GetCCFClass(venue),
+// openAlexBoolean 将不限字段的子树翻译为 OpenAlex search 的布尔语法，
+// 例如 (elmo AND "sesame street") NOT (cookie OR monster)
+func openAlexBoolean(n query.Node) (string, bool) {
+	switch v := n.(type) {
+	case *query.Term:
+		value := strings.ReplaceAll(v.Value, `"`, "")
+		if v.Phrase || strings.Contains(value, " ") {
+			return `"` + value + `"`, true
+		}
+		return value, true
+	case *query.And:
+		var pos, neg []string
+		for _, c := range v.Children {
+			if not, ok := c.(*query.Not); ok {
+				s, ok := openAlexBoolean(not.Child)
+				if !ok {
+					return "", false
+				}
+				neg = append(neg, s)
+				continue
+			}
+			s, ok := openAlexBoolean(c)
+			if !ok {
+				return "", false
+			}
+			pos = append(pos, s)
 		}
-		if paper.URL == "" {
-			paper.URL = item.ID
+		if len(pos) == 0 {
+			return "", false
+		}
+		s := strings.Join(pos, " AND ")
+		if len(pos) > 1 {
+			s = "(" + s + ")"
+		}
+		for _, n := range neg {
+			s += " NOT " + n
+		}
+		return s, true
+	case *query.Or:
+		parts := make([]string, 0, len(v.Children))
+		for _, c := range v.Children {
+			s, ok := openAlexBoolean(c)
+			if !ok {
+				return "", false
+			}
+			parts = append(parts, s)
 		}
-		papers = append(papers, paper)
+		return "(" + strings.Join(parts, " OR ") + ")", true
 	}
-	return papers, nil
+	return "", false
 }
@@ -0,0 +1,131 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"paper-scraper/internal/model"
+)
+
+// 引用与被引列表中向 S2 请求的字段（这两个接口不支持 tldr）
+const s2LinkedFields = "title,abstract,authors,venue,publicationVenue,year,publicationDate,externalIds," +
+	"citationCount,influentialCitationCount,fieldsOfStudy,s2FieldsOfStudy,openAccessPdf,url"
+
+// escapeID 转义路径中的 ID，保留 DOI 中的 "/"（OpenAlex 与 S2 均要求 DOI 原样出现在路径中）
+func escapeID(id string) string {
+	return strings.ReplaceAll(url.PathEscape(id), "%2F", "/")
+}
+
+// FetchOpenAlexWork 查询单篇 OpenAlex 论文，id 为 W 开头的 OpenAlex ID 或 "doi:10.xxx"。
+// 同时返回其参考文献的 OpenAlex ID
+func FetchOpenAlexWork(ctx context.Context, id string) (model.Paper, []string, error) {
+	resp, err := httpGet(ctx, "https://api.openalex.org/works/"+escapeID(id), nil)
+	if err != nil {
+		return model.Paper{}, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return model.Paper{}, nil, ErrNotFound
+	}
+	if resp.StatusCode != 200 {
+		return model.Paper{}, nil, fmt.Errorf("status code %d", resp.StatusCode)
+	}
+
+	var work model.OAWork
+	if err := json.NewDecoder(resp.Body).Decode(&work); err != nil {
+		return model.Paper{}, nil, err
+	}
+	refs := make([]string, 0, len(work.ReferencedWorks))
+	for _, ref := range work.ReferencedWorks {
+		refs = append(refs, NormalizeOpenAlexID(ref))
+	}
+	return openAlexToPaper(&work), refs, nil
+}
+
+// FetchOpenAlexWorksByID 按 OpenAlex ID 批量查询论文，未找到的不返回
+func FetchOpenAlexWorksByID(ctx context.Context, ids []string) ([]model.Paper, error) {
+	return fetchOpenAlexWorksBy(ctx, "openalex", ids)
+}
+
+// FetchOpenAlexCiting 返回引用了 id 的论文，按发布日期从新到旧，cursor 为空时从第一页开始。
+// 返回下一页的游标，没有更多结果时为空
+func FetchOpenAlexCiting(ctx context.Context, id string, limit int, cursor string) ([]model.Paper, string, error) {
+	if cursor == "" {
+		cursor = "*"
+	}
+	params := url.Values{}
+	params.Set("filter", "cites:"+id)
+	params.Set("sort", "publication_date:desc")
+	params.Set("per-page", strconv.Itoa(min(limit, openAlexMaxPerPage)))
+	params.Set("cursor", cursor)
+	papers, next, err := fetchOpenAlexWorks(ctx, params)
+	if len(papers) == 0 {
+		next = ""
+	}
+	return papers, next, err
+}
+
+// s2LinkedResponse 是 /paper/{id}/references 与 /paper/{id}/citations 的响应，
+// 论文分别位于 citedPaper 与 citingPaper 中
+type s2LinkedResponse struct {
+	Offset int `json:"offset"`
+	Next   int `json:"next,omitempty"`
+	Data   []struct {
+		CitedPaper  *S2Paper `json:"citedPaper"`
+		CitingPaper *S2Paper `json:"citingPaper"`
+	} `json:"data"`
+}
+
+// FetchS2Paper 查询单篇 S2 论文，id 为 paperId 或带前缀的外部 ID（如 "ARXIV:2106.12345"、"DOI:10.xxx"）
+func FetchS2Paper(ctx context.Context, id string) (model.Paper, error) {
+	var item S2Paper
+	if err := getS2JSON(ctx, "/paper/"+escapeID(id)+"?fields="+s2SearchFields, &item); err != nil {
+		return model.Paper{}, err
+	}
+	return s2ToPaper(&item), nil
+}
+
+// FetchS2References 返回论文的参考文献（最多 limit 篇），S2 未能解析的参考文献不返回
+func FetchS2References(ctx context.Context, id string, limit int) ([]model.Paper, error) {
+	papers, _, err := fetchS2Linked(ctx, id, "references", limit, 0)
+	return papers, err
+}
+
+// FetchS2Citations 返回引用了该论文的论文，offset 为分页位置；返回下一页的 offset，没有更多结果时为空
+func FetchS2Citations(ctx context.Context, id string, limit, offset int) ([]model.Paper, string, error) {
+	return fetchS2Linked(ctx, id, "citations", limit, offset)
+}
+
+func fetchS2Linked(ctx context.Context, id, kind string, limit, offset int) ([]model.Paper, string, error) {
+	params := url.Values{}
+	params.Set("fields", s2LinkedFields)
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("limit", strconv.Itoa(min(limit, s2MaxPerPage)))
+
+	var s2Resp s2LinkedResponse
+	if err := getS2JSON(ctx, "/paper/"+escapeID(id)+"/"+kind+"?"+params.Encode(), &s2Resp); err != nil {
+		return nil, "", err
+	}
+	papers := make([]model.Paper, 0, len(s2Resp.Data))
+	for _, item := range s2Resp.Data {
+		linked := item.CitedPaper
+		if kind == "citations" {
+			linked = item.CitingPaper
+		}
+		if linked == nil || linked.PaperID == "" || linked.Title == "" {
+			continue
+		}
+		papers = append(papers, s2ToPaper(linked))
+	}
+	next := ""
+	if s2Resp.Next > offset {
+		next = strconv.Itoa(s2Resp.Next)
+	}
+	return papers, next, nil
+}
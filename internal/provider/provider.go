@@ -0,0 +1,160 @@
+package provider
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/query"
+)
+
+// Query 是传递给各数据源的标准化检索参数
+type Query struct {
+	Text      string     // 关键词形式的查询，供不支持字段检索的数据源使用
+	Expr      query.Node // 解析后的检索表达式，可为 nil；数据源无法表达的条件需做后置过滤
+	Limit     int
+	Offset    int
+	StartDate string // YYYY-MM-DD，可为空
+	EndDate   string // YYYY-MM-DD，可为空
+	Sort      string // published_desc / published_asc / relevance / citations
+
+	Categories []string // arXiv 分类（如 cs.DC、cs.CR），满足其一即可；为空表示整个 cs.*
+	Concepts   []string // OpenAlex concept ID（如 C38652104），满足其一即可
+}
+
+// MatchesCategories 判断论文是否属于 categories 中的任一分类，categories 为空时总是成立
+func MatchesCategories(p model.Paper, categories []string) bool {
+	if len(categories) == 0 {
+		return true
+	}
+	for _, c := range p.Categories {
+		for _, want := range categories {
+			if strings.EqualFold(c, want) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Capabilities 描述数据源原生支持的检索能力，
+// 不支持的部分由调用方在结果上做后置处理
+type Capabilities struct {
+	DateRange bool // 支持按发布日期范围过滤
+	Sort      bool // 支持按发布时间排序
+	Offset    bool // 支持偏移量分页
+	// Persistent 表示结果本身来自本地论文库，调用方不应再写回论文库
+	Persistent bool
+}
+
+// Provider 是所有论文数据源的统一接口
+type Provider interface {
+	Name() string
+	Capabilities() Capabilities
+	Search(ctx context.Context, q Query) ([]model.Paper, error)
+}
+
+// Page 是一次游标分页检索的结果
+type Page struct {
+	Papers []model.Paper
+	Next   string // 下一页的位置，为空表示没有更多结果
+}
+
+// Pager 是支持游标分页的数据源。cursor 为上一页返回的 Next，空串表示第一页。
+// 同一 cursor 的重复请求应返回相同的结果，调用方据此在页内记录已消费的条数
+type Pager interface {
+	SearchPage(ctx context.Context, q Query, cursor string) (Page, error)
+}
+
+// SearchPage 以游标分页方式检索 p；未实现 Pager 的数据源退化为按偏移量分页，
+// cursor 即偏移量，返回条数不足 Limit 时视为最后一页
+func SearchPage(ctx context.Context, p Provider, q Query, cursor string) (Page, error) {
+	if pager, ok := p.(Pager); ok {
+		return pager.SearchPage(ctx, q, cursor)
+	}
+	offset, _ := strconv.Atoi(cursor)
+	q.Offset = offset
+	papers, err := p.Search(ctx, q)
+	if err != nil {
+		return Page{}, err
+	}
+	return Page{Papers: papers, Next: nextOffset(offset, len(papers), q.Limit)}, nil
+}
+
+// nextOffset 根据本页从上游取得的原始条数计算下一页的偏移量（未经后置过滤的条数）
+func nextOffset(offset, fetched, limit int) string {
+	if fetched == 0 || limit <= 0 || fetched < limit {
+		return ""
+	}
+	return strconv.Itoa(offset + fetched)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Provider)
+)
+
+// Register 注册一个数据源，同名数据源会被覆盖（便于测试时替换为假实现）
+func Register(p Provider) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(p.Name())] = p
+}
+
+// Unregister 移除已注册的数据源
+func Unregister(name string) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	delete(registry, strings.ToLower(name))
+}
+
+// Get 按名称查找数据源
+func Get(name string) (Provider, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	p, ok := registry[strings.ToLower(strings.TrimSpace(name))]
+	return p, ok
+}
+
+// Names 返回所有已注册数据源的名称（按字母排序）
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve 将 sources 参数（可能为逗号分隔）解析为已注册的数据源，
+// 去重并保持请求中的顺序；未注册的名称通过 unknown 返回
+func Resolve(names []string) (providers []Provider, unknown []string) {
+	seen := make(map[string]bool)
+	for _, raw := range names {
+		for _, name := range strings.Split(raw, ",") {
+			name = strings.ToLower(strings.TrimSpace(name))
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			if p, ok := Get(name); ok {
+				providers = append(providers, p)
+			} else {
+				unknown = append(unknown, name)
+			}
+		}
+	}
+	return providers, unknown
+}
+
+func init() {
+	Register(arxivProvider{})
+	Register(openAlexProvider{})
+	Register(dblpProvider{})
+	Register(s2Provider{})
+}
@@ -0,0 +1,73 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"paper-scraper/internal/model"
+)
+
+// fakeProvider 按偏移量返回固定的论文列表，不实现 Pager
+type fakeProvider struct {
+	name   string
+	papers []model.Paper
+}
+
+func (f fakeProvider) Name() string               { return f.name }
+func (f fakeProvider) Capabilities() Capabilities { return Capabilities{Offset: true} }
+
+func (f fakeProvider) Search(ctx context.Context, q Query) ([]model.Paper, error) {
+	if q.Offset >= len(f.papers) {
+		return nil, nil
+	}
+	return f.papers[q.Offset:min(q.Offset+q.Limit, len(f.papers))], nil
+}
+
+func TestRegistry(t *testing.T) {
+	fake := fakeProvider{name: "Fake"}
+	Register(fake)
+	defer Unregister("fake")
+
+	if _, ok := Get(" FAKE "); !ok {
+		t.Fatal("registered provider not found")
+	}
+	providers, unknown := Resolve([]string{"fake,arxiv", "fake", "nosuch"})
+	if len(providers) != 2 || providers[0].Name() != "Fake" || providers[1].Name() != "arxiv" {
+		t.Errorf("Resolve providers = %v, want [Fake arxiv]", providers)
+	}
+	if len(unknown) != 1 || unknown[0] != "nosuch" {
+		t.Errorf("Resolve unknown = %v, want [nosuch]", unknown)
+	}
+
+	Unregister("fake")
+	if _, ok := Get("fake"); ok {
+		t.Error("provider still registered after Unregister")
+	}
+}
+
+func TestSearchPageOffsetFallback(t *testing.T) {
+	var papers []model.Paper
+	for i := 0; i < 5; i++ {
+		papers = append(papers, model.Paper{ID: string(rune('a' + i))})
+	}
+	p := fakeProvider{name: "fake", papers: papers}
+
+	var got []string
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		page, err := SearchPage(context.Background(), p, Query{Limit: 2}, cursor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, paper := range page.Papers {
+			got = append(got, paper.ID)
+		}
+		if page.Next == "" {
+			break
+		}
+		cursor = page.Next
+	}
+	if len(got) != 5 {
+		t.Errorf("paged through %v, want all 5 papers once", got)
+	}
+}
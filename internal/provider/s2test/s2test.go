@@ -0,0 +1,310 @@
+// Package s2test 提供 Semantic Scholar Graph API 的本地替身服务，
+// 实现 /paper/search、/paper/batch 与 /author 系列接口（作者由论文的作者列表汇总），供测试与离线开发使用：
+//
+//	srv := s2test.NewServer(papers...)
+//	defer srv.Close()
+//	provider.ConfigureS2(srv.URL, "")
+package s2test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"paper-scraper/internal/provider"
+)
+
+// batchLimit 与 S2 批量接口的 ID 上限一致
+const batchLimit = 500
+
+// Server 是运行在本地端口上的 S2 替身
+type Server struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	apiKey   string
+	papers   []provider.S2Paper
+	failures []int
+	requests int
+}
+
+// NewServer 启动替身服务，papers 为可被检索到的论文
+func NewServer(papers ...provider.S2Paper) *Server {
+	s := &Server{papers: papers}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/paper/search", s.handleSearch)
+	mux.HandleFunc("/paper/batch", s.handleBatch)
+	mux.HandleFunc("/author/search", s.handleAuthorSearch)
+	mux.HandleFunc("/author/", s.handleAuthor)
+	s.Server = httptest.NewServer(s.wrap(mux))
+	return s
+}
+
+// Add 追加论文
+func (s *Server) Add(papers ...provider.S2Paper) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.papers = append(s.papers, papers...)
+}
+
+// RequireAPIKey 要求请求携带匹配的 x-api-key，否则返回 403
+func (s *Server) RequireAPIKey(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apiKey = key
+}
+
+// FailNext 让接下来的请求依次以给定状态码失败（例如 429），用于验证重试逻辑
+func (s *Server) FailNext(statuses ...int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = append(s.failures, statuses...)
+}
+
+// Requests 返回已收到的请求数
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests
+}
+
+func (s *Server) wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests++
+		apiKey := s.apiKey
+		status := 0
+		if len(s.failures) > 0 {
+			status, s.failures = s.failures[0], s.failures[1:]
+		}
+		s.mu.Unlock()
+
+		if apiKey != "" && r.Header.Get("x-api-key") != apiKey {
+			writeJSON(w, http.StatusForbidden, map[string]string{"message": "Forbidden"})
+			return
+		}
+		if status != 0 {
+			if status == http.StatusTooManyRequests {
+				w.Header().Set("Retry-After", "0")
+			}
+			writeJSON(w, status, map[string]string{"message": http.StatusText(status)})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) snapshot() []provider.S2Paper {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]provider.S2Paper(nil), s.papers...)
+}
+
+func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "method not allowed"})
+		return
+	}
+	q := r.URL.Query()
+	terms := strings.Fields(strings.ToLower(q.Get("query")))
+	if len(terms) == 0 {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "query is required"})
+		return
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	from, to, _ := strings.Cut(q.Get("publicationDateOrYear"), ":")
+
+	var matched []provider.S2Paper
+	for _, p := range s.snapshot() {
+		text := strings.ToLower(p.Title + " " + p.Abstract)
+		ok := true
+		for _, t := range terms {
+			if !strings.Contains(text, t) {
+				ok = false
+				break
+			}
+		}
+		if ok && inRange(p, from, to) {
+			matched = append(matched, p)
+		}
+	}
+
+	resp := provider.S2SearchResponse{Total: len(matched), Offset: offset, Data: []provider.S2Paper{}}
+	if offset < len(matched) {
+		end := offset + limit
+		if end > len(matched) {
+			end = len(matched)
+		} else if end < len(matched) {
+			resp.Next = end
+		}
+		resp.Data = matched[offset:end]
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// inRange 按 publicationDateOrYear 的语义比较日期，两端均可为空
+func inRange(p provider.S2Paper, from, to string) bool {
+	date := p.PublicationDate
+	if date == "" && p.Year != 0 {
+		date = strconv.Itoa(p.Year)
+	}
+	if from == "" && to == "" {
+		return true
+	}
+	if date == "" {
+		return false
+	}
+	if from != "" && date < from[:min(len(from), len(date))] {
+		return false
+	}
+	if to != "" && date[:min(len(to), len(date))] > to {
+		return false
+	}
+	return true
+}
+
+func (s *Server) handleBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeJSON(w, http.StatusMethodNotAllowed, map[string]string{"message": "method not allowed"})
+		return
+	}
+	var body struct {
+		IDs []string `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	if len(body.IDs) > batchLimit {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "Cannot process more than 500 ids"})
+		return
+	}
+
+	papers := s.snapshot()
+	results := make([]*provider.S2Paper, len(body.IDs))
+	for i, id := range body.IDs {
+		for j := range papers {
+			if matchesID(&papers[j], id) {
+				results[i] = &papers[j]
+				break
+			}
+		}
+	}
+	writeJSON(w, http.StatusOK, results)
+}
+
+// matchesID 支持 S2 的 ID 写法：paperId、ARXIV:、DOI:、CorpusId:
+func matchesID(p *provider.S2Paper, id string) bool {
+	prefix, value, ok := strings.Cut(id, ":")
+	if !ok {
+		return p.PaperID == id
+	}
+	switch strings.ToUpper(prefix) {
+	case "ARXIV":
+		return strings.EqualFold(p.ExternalIDs["ArXiv"], value)
+	case "DOI":
+		return strings.EqualFold(p.ExternalIDs["DOI"], value)
+	case "CORPUSID":
+		return p.ExternalIDs["CorpusId"] == value
+	}
+	return p.PaperID == id
+}
+
+// authors 汇总论文中出现的作者，按首次出现的顺序返回
+func (s *Server) authors() ([]provider.S2AuthorRecord, map[string][]provider.S2Paper) {
+	var records []provider.S2AuthorRecord
+	papers := make(map[string][]provider.S2Paper)
+	for _, p := range s.snapshot() {
+		for _, a := range p.Authors {
+			if a.AuthorID == "" {
+				continue
+			}
+			if _, ok := papers[a.AuthorID]; !ok {
+				records = append(records, provider.S2AuthorRecord{AuthorID: a.AuthorID, Name: a.Name})
+			}
+			papers[a.AuthorID] = append(papers[a.AuthorID], p)
+		}
+	}
+	for i := range records {
+		var citations []int
+		for _, p := range papers[records[i].AuthorID] {
+			records[i].CitationCount += p.CitationCount
+			citations = append(citations, p.CitationCount)
+		}
+		records[i].PaperCount = len(citations)
+		sort.Sort(sort.Reverse(sort.IntSlice(citations)))
+		for h, c := range citations {
+			if c >= h+1 {
+				records[i].HIndex = h + 1
+			}
+		}
+	}
+	return records, papers
+}
+
+func (s *Server) handleAuthorSearch(w http.ResponseWriter, r *http.Request) {
+	q := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("query")))
+	if q == "" {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "query is required"})
+		return
+	}
+	records, _ := s.authors()
+	resp := provider.S2AuthorSearchResponse{Data: []provider.S2AuthorRecord{}}
+	for _, a := range records {
+		if strings.Contains(strings.ToLower(a.Name), q) {
+			resp.Data = append(resp.Data, a)
+		}
+	}
+	resp.Total = len(resp.Data)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// handleAuthor 实现 /author/{id} 与 /author/{id}/papers
+func (s *Server) handleAuthor(w http.ResponseWriter, r *http.Request) {
+	id, sub, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/author/"), "/")
+	records, papers := s.authors()
+	for _, a := range records {
+		if a.AuthorID != id {
+			continue
+		}
+		switch sub {
+		case "":
+			writeJSON(w, http.StatusOK, a)
+		case "papers":
+			q := r.URL.Query()
+			offset, _ := strconv.Atoi(q.Get("offset"))
+			limit, err := strconv.Atoi(q.Get("limit"))
+			if err != nil || limit <= 0 {
+				limit = 100
+			}
+			list := papers[id]
+			resp := provider.S2AuthorPapersResponse{Offset: offset, Data: []provider.S2Paper{}}
+			if offset < len(list) {
+				end := min(offset+limit, len(list))
+				if end < len(list) {
+					resp.Next = end
+				}
+				resp.Data = list[offset:end]
+			}
+			writeJSON(w, http.StatusOK, resp)
+		default:
+			writeJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		}
+		return
+	}
+	writeJSON(w, http.StatusNotFound, map[string]string{"error": "Author not found"})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -1,75 +1,203 @@
 package provider
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
-	"time"
+	"sync"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/query"
 )
 
-type S2Paper struct {
-	PaperID       string `json:"paperId"`
-	CitationCount int    `json:"citationCount"`
+// s2BatchLimit 是 S2 批量接口单次请求允许的 ID 上限
+const s2BatchLimit = 500
+
+// 批量补全时向 S2 请求的字段
+const s2BatchFields = "citationCount,influentialCitationCount,venue,publicationVenue,externalIds,authors"
+
+// 检索时向 S2 请求的字段
+const s2SearchFields = "title,abstract,authors,venue,publicationVenue,year,publicationDate,externalIds," +
+	"citationCount,influentialCitationCount,fieldsOfStudy,s2FieldsOfStudy,openAccessPdf,tldr,url"
+
+var (
+	s2Mu      sync.RWMutex
+	s2BaseURL = "https://api.semanticscholar.org/graph/v1"
+	s2APIKey  string
+)
+
+// ConfigureS2 设置 Semantic Scholar 的接口地址与 API key，空值保持原配置。
+// 测试时可将 baseURL 指向本地替身服务（见 internal/provider/s2test）
+func ConfigureS2(baseURL, apiKey string) {
+	s2Mu.Lock()
+	defer s2Mu.Unlock()
+	if baseURL != "" {
+		s2BaseURL = strings.TrimRight(baseURL, "/")
+	}
+	if apiKey != "" {
+		s2APIKey = apiKey
+	}
 }
 
-func FetchCitations(arxivIDs []string) (map[string]int, error) {
-	if len(arxivIDs) == 0 {
-		return nil, nil
+// s2Endpoint 返回接口地址与请求头（带 API key 时附加 x-api-key）
+func s2Endpoint(path string) (string, http.Header) {
+	s2Mu.RLock()
+	defer s2Mu.RUnlock()
+	header := http.Header{}
+	if s2APIKey != "" {
+		header.Set("x-api-key", s2APIKey)
 	}
+	return s2BaseURL + path, header
+}
 
-	// 准备 ID
-	// S2 期望格式为 "ARXIV:ID"。
-	// 我们的 ID 通常是像 "http://arxiv.org/abs/2106.12345v1" 这样的 URL
-	// 我们需要提取 ID 部分。
+type s2Provider struct{}
 
-	payloadIDs := make([]string, 0, len(arxivIDs))
-	// 从 S2 请求 ID 映射到原始 ID（或者我们可以直接匹配剥离后的 ID）
-	// 实际上，S2 返回的是 "paperId"，这是它的内部 ID (SHA)，而不是我们发送的 arXiv ID。
-	// 等等。响应是对应输入列表顺序的对象列表吗？还是 null？
-	// 批量端点返回一个对象数组。
-	// 文档说明：“返回论文对象列表，顺序与输入 ID 相同。”
-	// 如果未找到论文，该条目为 null。
+func (s2Provider) Name() string { return "s2" }
 
-	for _, rawID := range arxivIDs {
-		// 清洗 ID
-		cleanID := rawID
-		cleanID = strings.TrimPrefix(cleanID, "http://arxiv.org/abs/")
-		cleanID = strings.TrimPrefix(cleanID, "https://arxiv.org/abs/")
-		// 移除版本后缀（例如 v1, v2）以获得更好的 S2 匹配
-		if idx := strings.LastIndex(cleanID, "v"); idx > 0 && idx < len(cleanID)-1 {
-			// 检查后续字符是否为数字
-			isVersion := true
-			for _, r := range cleanID[idx+1:] {
-				if r < '0' || r > '9' {
-					isVersion = false
-					break
-				}
+func (s2Provider) Capabilities() Capabilities {
+	// S2 检索按相关度排序，支持按发布日期过滤与偏移分页
+	return Capabilities{DateRange: true, Offset: true}
+}
+
+func (s s2Provider) Search(ctx context.Context, q Query) ([]model.Paper, error) {
+	page, err := s.SearchPage(ctx, q, strconv.Itoa(q.Offset))
+	return page.Papers, err
+}
+
+// SearchPage 以 S2 的 offset 作为分页位置，下一页位置取自响应中的 next
+func (s2Provider) SearchPage(ctx context.Context, q Query, cursor string) (Page, error) {
+	offset, _ := strconv.Atoi(cursor)
+	papers, next, err := fetchS2Search(ctx, q.Text, q.Limit, offset, q.StartDate, q.EndDate)
+	if err != nil {
+		return Page{}, err
+	}
+	// S2 只支持关键词检索，字段、年份与否定条件均在结果上后置过滤
+	return Page{Papers: query.Filter(q.Expr, papers), Next: next}, nil
+}
+
+type S2Paper struct {
+	PaperID                  string           `json:"paperId"`
+	Title                    string           `json:"title,omitempty"`
+	Abstract                 string           `json:"abstract,omitempty"`
+	Authors                  []S2Author       `json:"authors,omitempty"`
+	Year                     int              `json:"year,omitempty"`
+	PublicationDate          string           `json:"publicationDate,omitempty"`
+	URL                      string           `json:"url,omitempty"`
+	CitationCount            int              `json:"citationCount"`
+	InfluentialCitationCount int              `json:"influentialCitationCount"`
+	Venue                    string           `json:"venue"`
+	PublicationVenue         *S2Venue         `json:"publicationVenue"`
+	ExternalIDs              S2ExternalIDs    `json:"externalIds"`
+	FieldsOfStudy            []string         `json:"fieldsOfStudy,omitempty"`
+	S2FieldsOfStudy          []S2FieldOfStudy `json:"s2FieldsOfStudy,omitempty"`
+	OpenAccessPDF            *S2OpenAccessPDF `json:"openAccessPdf,omitempty"`
+	TLDR                     *S2TLDR          `json:"tldr,omitempty"`
+}
+
+type S2Author struct {
+	AuthorID string `json:"authorId"`
+	Name     string `json:"name"`
+}
+
+type S2FieldOfStudy struct {
+	Category string `json:"category"`
+	Source   string `json:"source"`
+}
+
+type S2OpenAccessPDF struct {
+	URL    string `json:"url"`
+	Status string `json:"status"`
+}
+
+type S2TLDR struct {
+	Model string `json:"model"`
+	Text  string `json:"text"`
+}
+
+// S2SearchResponse 是 /paper/search 的响应
+type S2SearchResponse struct {
+	Total  int       `json:"total"`
+	Offset int       `json:"offset"`
+	Next   int       `json:"next,omitempty"`
+	Data   []S2Paper `json:"data"`
+}
+
+type S2Venue struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type"` // journal / conference
+}
+
+// S2ExternalIDs 是论文或作者在其他库中的 ID。S2 返回的 CorpusId 为数字，作者的部分 ID 为字符串数组（取第一个），
+// 其余为字符串，统一转为字符串
+type S2ExternalIDs map[string]string
+
+func (ids *S2ExternalIDs) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	out := make(S2ExternalIDs, len(raw))
+	for k, v := range raw {
+		var s string
+		if err := json.Unmarshal(v, &s); err == nil {
+			if s != "" {
+				out[k] = s
 			}
-			if isVersion {
-				cleanID = cleanID[:idx]
+			continue
+		}
+		var n json.Number
+		if err := json.Unmarshal(v, &n); err == nil {
+			out[k] = n.String()
+			continue
+		}
+		var list []string
+		if err := json.Unmarshal(v, &list); err == nil && len(list) > 0 && list[0] != "" {
+			out[k] = list[0]
+		}
+	}
+	*ids = out
+	return nil
+}
+
+// FetchS2Batch 通过 S2 批量接口查询论文，ids 形如 "ARXIV:2106.12345" 或 "DOI:10.1109/..."。
+// 超过 500 个 ID 时分批请求；返回结果与 ids 一一对应，未找到的为 nil。
+// 某一批失败时返回已取得的结果与该错误
+func FetchS2Batch(ctx context.Context, ids []string) ([]*S2Paper, error) {
+	results := make([]*S2Paper, len(ids))
+	for start := 0; start < len(ids); start += s2BatchLimit {
+		end := start + s2BatchLimit
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch, err := fetchS2Chunk(ctx, ids[start:end])
+		if err != nil {
+			return results, err
+		}
+		// 批量端点按输入顺序返回对象数组，未找到的论文为 null
+		for i, res := range batch {
+			if start+i < end {
+				results[start+i] = res
 			}
 		}
-		// 通常 ARXIV:ID 是有效的。
-		payloadIDs = append(payloadIDs, "ARXIV:"+cleanID)
 	}
+	return results, nil
+}
 
+func fetchS2Chunk(ctx context.Context, ids []string) ([]*S2Paper, error) {
 	requestBody, err := json.Marshal(map[string][]string{
-		"ids": payloadIDs,
+		"ids": ids,
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", "https://api.semanticscholar.org/graph/v1/paper/batch?fields=citationCount", bytes.NewBuffer(requestBody))
-	if err != nil {
-		return nil, err
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	client := http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	endpoint, header := s2Endpoint("/paper/batch?fields=" + s2BatchFields)
+	resp, err := httpPost(ctx, endpoint, header, requestBody)
 	if err != nil {
 		return nil, err
 	}
@@ -79,25 +207,255 @@ func FetchCitations(arxivIDs []string) (map[string]int, error) {
 		return nil, fmt.Errorf("S2 status %d", resp.StatusCode)
 	}
 
-	// 注意：S2 可能会对某些条目返回 null。
-	// 我们需要解码为指针切片或 raw json.RawMessage 来处理 null 吗？
-	// 或者只是结构体切片，如果是 null，它可能会报错或是零值？
-	// “如果未找到论文，列表中的相应条目将为 null。”
-	// 所以我们需要解码为 []*S2Paper
 	var rawResults []*S2Paper
 	if err := json.NewDecoder(resp.Body).Decode(&rawResults); err != nil {
 		return nil, err
 	}
+	return rawResults, nil
+}
+
+// FetchS2Search 调用 S2 的论文检索接口，限定计算机科学领域
+func FetchS2Search(ctx context.Context, query string, limit, offset int, startDate, endDate string) ([]model.Paper, error) {
+	papers, _, err := fetchS2Search(ctx, query, limit, offset, startDate, endDate)
+	return papers, err
+}
+
+// fetchS2Search 同 FetchS2Search，并返回下一页的 offset（没有更多结果时为空）
+func fetchS2Search(ctx context.Context, query string, limit, offset int, startDate, endDate string) ([]model.Paper, string, error) {
+	if strings.TrimSpace(query) == "" {
+		// S2 不支持空查询
+		return nil, "", nil
+	}
+	// S2 检索接口单次最多返回 100 条
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("offset", strconv.Itoa(offset))
+	params.Set("limit", strconv.Itoa(limit))
+	params.Set("fields", s2SearchFields)
+	params.Set("fieldsOfStudy", "Computer Science")
+	if startDate != "" || endDate != "" {
+		params.Set("publicationDateOrYear", startDate+":"+endDate)
+	}
+
+	endpoint, header := s2Endpoint("/paper/search?" + params.Encode())
+	resp, err := httpGet(ctx, endpoint, header)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, "", fmt.Errorf("S2 status %d", resp.StatusCode)
+	}
+
+	var s2Resp S2SearchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&s2Resp); err != nil {
+		return nil, "", err
+	}
+
+	papers := make([]model.Paper, 0, len(s2Resp.Data))
+	for i := range s2Resp.Data {
+		papers = append(papers, s2ToPaper(&s2Resp.Data[i]))
+	}
+	next := ""
+	if s2Resp.Next > 0 {
+		next = strconv.Itoa(s2Resp.Next)
+	}
+	return papers, next, nil
+}
+
+func s2ToPaper(item *S2Paper) model.Paper {
+	var authors []model.Author
+	for _, a := range item.Authors {
+		if a.Name != "" {
+			authors = append(authors, model.Author{Name: a.Name, S2ID: a.AuthorID})
+		}
+	}
+
+	// 领域：优先 fieldsOfStudy，其次 s2FieldsOfStudy 的类别
+	var categories []string
+	for _, f := range item.FieldsOfStudy {
+		categories = appendUniqueString(categories, f)
+	}
+	for _, f := range item.S2FieldsOfStudy {
+		categories = appendUniqueString(categories, f.Category)
+	}
+
+	p := model.Paper{
+		ID:            item.PaperID,
+		Title:         item.Title,
+		Authors:       AuthorNames(authors),
+		AuthorDetails: authors,
+		Abstract:      item.Abstract,
+		URL:           item.URL,
+		Source:        "s2",
+		Categories:    categories,
+		PublishedAt:   item.PublicationDate,
+		CCFClass:      "None",
+	}
+	if item.Year != 0 {
+		year := item.Year
+		p.Year = &year
+		if p.PublishedAt == "" {
+			p.PublishedAt = strconv.Itoa(year)
+		}
+	}
+	if item.OpenAccessPDF != nil {
+		p.PDFURL = item.OpenAccessPDF.URL
+	}
+	if item.TLDR != nil {
+		p.TLDR = item.TLDR.Text
+	}
+	applyS2(&p, item)
+	if p.Venue == "" {
+		p.Venue = "Semantic Scholar"
+	}
+	if p.URL == "" && p.ArxivID != "" {
+		p.URL = "https://arxiv.org/abs/" + p.ArxivID
+	}
+	return p
+}
+
+func appendUniqueString(list []string, s string) []string {
+	if s == "" {
+		return list
+	}
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// FetchCitations 查询一组 arXiv 论文的引用量，返回 原始 ID -> 引用量
+func FetchCitations(ctx context.Context, arxivIDs []string) (map[string]int, error) {
+	if len(arxivIDs) == 0 {
+		return nil, nil
+	}
+
+	payloadIDs := make([]string, 0, len(arxivIDs))
+	for _, rawID := range arxivIDs {
+		// 清洗 ID，移除版本后缀（例如 v1, v2）以获得更好的 S2 匹配
+		cleanID := ExtractArxivID(rawID)
+		if cleanID == "" {
+			cleanID = rawID
+		}
+		payloadIDs = append(payloadIDs, "ARXIV:"+cleanID)
+	}
 
+	results, err := FetchS2Batch(ctx, payloadIDs)
 	citationMap := make(map[string]int)
-	for i, res := range rawResults {
+	for i, res := range results {
 		if res != nil {
 			// 映射回原始 ArXiv ID（使用索引）
-			if i < len(arxivIDs) {
-				citationMap[arxivIDs[i]] = res.CitationCount
-			}
+			citationMap[arxivIDs[i]] = res.CitationCount
+		}
+	}
+	return citationMap, err
+}
+
+// s2LookupID 返回用于 S2 批量查询的 ID：优先 arXiv ID，其次 DOI
+func s2LookupID(p model.Paper) string {
+	if p.S2ID != "" {
+		return p.S2ID
+	}
+	for _, candidate := range []string{p.ArxivID, p.ID, p.URL, p.DOI} {
+		if id := ExtractArxivID(candidate); id != "" {
+			return "ARXIV:" + id
+		}
+	}
+	if doi := NormalizeDOI(p.DOI); doi != "" {
+		return "DOI:" + doi
+	}
+	return ""
+}
+
+// EnrichS2 用 Semantic Scholar 补全论文的引用量、有影响力引用量、venue、venue 类型与外部 ID。
+// papers 原地修改，返回成功匹配的论文数
+func EnrichS2(ctx context.Context, papers []model.Paper) (int, error) {
+	// 同一 ID 可能对应多条记录（例如未合并的结果），只查询一次
+	positions := make(map[string][]int)
+	var ids []string
+	for i, p := range papers {
+		id := s2LookupID(p)
+		if id == "" {
+			continue
+		}
+		if _, ok := positions[id]; !ok {
+			ids = append(ids, id)
+		}
+		positions[id] = append(positions[id], i)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	results, err := FetchS2Batch(ctx, ids)
+	matched := 0
+	for i, res := range results {
+		if res == nil {
+			continue
+		}
+		for _, pos := range positions[ids[i]] {
+			applyS2(&papers[pos], res)
+			matched++
 		}
 	}
+	return matched, err
+}
+
+func applyS2(p *model.Paper, res *S2Paper) {
+	if res.PaperID != "" {
+		p.S2ID = res.PaperID
+	}
+	// 各来源的引用量更新节奏不同，取较大值
+	if res.CitationCount > p.Citations {
+		p.Citations = res.CitationCount
+	}
+	if res.InfluentialCitationCount > p.InfluentialCitations {
+		p.InfluentialCitations = res.InfluentialCitationCount
+	}
 
-	return citationMap, nil
+	if len(res.ExternalIDs) > 0 {
+		if p.ExternalIDs == nil {
+			p.ExternalIDs = make(map[string]string, len(res.ExternalIDs))
+		}
+		for k, v := range res.ExternalIDs {
+			p.ExternalIDs[k] = v
+		}
+	}
+	if p.DOI == "" {
+		p.DOI = NormalizeDOI(res.ExternalIDs["DOI"])
+	}
+	if p.ArxivID == "" {
+		p.ArxivID = res.ExternalIDs["ArXiv"]
+	}
+	// 为已有作者补全 S2 authorId
+	if len(res.Authors) > 0 && len(p.Authors) > 0 {
+		s2Authors := make([]model.Author, 0, len(res.Authors))
+		for _, a := range res.Authors {
+			s2Authors = append(s2Authors, model.Author{Name: a.Name, S2ID: a.AuthorID})
+		}
+		p.AuthorDetails = ReconcileAuthors(AuthorsOf(*p), s2Authors)
+	}
+
+	venue := res.Venue
+	if res.PublicationVenue != nil {
+		if res.PublicationVenue.Name != "" {
+			venue = res.PublicationVenue.Name
+		}
+		if res.PublicationVenue.Type != "" {
+			p.VenueType = strings.ToLower(res.PublicationVenue.Type)
+		}
+	}
+	// 仅在现有 venue 只是占位（如 "arXiv"）或取自 arXiv 作者注释（如 "12 pages, 4 figures"）时采用 S2 的 venue
+	if venue != "" && (IsPlaceholderVenue(p.Venue) || IsCommentVenue(*p)) && !IsPlaceholderVenue(venue) {
+		p.Venue = venue
+		p.CCFClass = GetCCFClass(venue)
+	}
 }
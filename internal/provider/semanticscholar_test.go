@@ -0,0 +1,104 @@
+package provider_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+	"paper-scraper/internal/provider/s2test"
+	"paper-scraper/internal/query"
+)
+
+func TestS2SearchWithStandIn(t *testing.T) {
+	srv := s2test.NewServer(
+		provider.S2Paper{PaperID: "p1", Title: "Diffusion models beat GANs", PublicationDate: "2024-03-01", Year: 2024,
+			Authors: []provider.S2Author{{AuthorID: "1", Name: "Ada Lovelace"}}},
+		provider.S2Paper{PaperID: "p2", Title: "Latent diffusion", PublicationDate: "2024-02-01", Year: 2024},
+		provider.S2Paper{PaperID: "p3", Title: "Score-based diffusion", PublicationDate: "2023-12-01", Year: 2023},
+		provider.S2Paper{PaperID: "p4", Title: "Graph neural networks", PublicationDate: "2024-01-01", Year: 2024},
+	)
+	defer srv.Close()
+	provider.ConfigureS2(srv.URL, "")
+
+	s2, ok := provider.Get("s2")
+	if !ok {
+		t.Fatal("s2 provider not registered")
+	}
+	// 第一次请求被限流，共享的 HTTP 客户端应重试
+	srv.FailNext(http.StatusTooManyRequests)
+
+	seen := make(map[string]int)
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		page, err := provider.SearchPage(context.Background(), s2, provider.Query{Text: "diffusion", Limit: 2}, cursor)
+		if err != nil {
+			t.Fatalf("SearchPage: %v", err)
+		}
+		for _, p := range page.Papers {
+			if p.Source != "s2" {
+				t.Errorf("paper %s has source %q, want s2", p.S2ID, p.Source)
+			}
+			seen[p.S2ID]++
+		}
+		if page.Next == "" {
+			break
+		}
+		cursor = page.Next
+	}
+	for _, id := range []string{"p1", "p2", "p3"} {
+		if seen[id] != 1 {
+			t.Errorf("paper %s returned %d times, want 1", id, seen[id])
+		}
+	}
+	if seen["p4"] != 0 {
+		t.Error("non-matching paper returned")
+	}
+
+	// 后置过滤：年份条件由 S2 的结果在本地过滤
+	papers, err := s2.Search(context.Background(), provider.Query{Text: "diffusion", Expr: mustParse(t, "diffusion year:2023"), Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(papers) != 1 || papers[0].S2ID != "p3" {
+		t.Errorf("year-filtered search = %v, want only p3", papers)
+	}
+}
+
+func TestEnrichS2ReplacesCommentVenue(t *testing.T) {
+	srv := s2test.NewServer(provider.S2Paper{
+		PaperID:          "p1",
+		Title:            "Some vision paper",
+		CitationCount:    42,
+		Venue:            "CVPR",
+		PublicationVenue: &provider.S2Venue{Name: "CVPR", Type: "conference"},
+		ExternalIDs:      provider.S2ExternalIDs{"ArXiv": "2401.00001"},
+	})
+	defer srv.Close()
+	provider.ConfigureS2(srv.URL, "")
+
+	comment := "12 pages, 4 figures"
+	papers := []model.Paper{
+		{Title: "Some vision paper", Source: "arxiv", ArxivID: "2401.00001", URL: "http://arxiv.org/abs/2401.00001v1", Venue: comment, Comment: comment},
+		{Title: "Published elsewhere", Source: "arxiv", ArxivID: "2401.00001", URL: "http://arxiv.org/abs/2401.00001v1", Venue: "ICCV", Comment: comment},
+	}
+	if _, err := provider.EnrichS2(context.Background(), papers); err != nil {
+		t.Fatalf("EnrichS2: %v", err)
+	}
+	if papers[0].Venue != "CVPR" || papers[0].CCFClass != "A" || papers[0].Citations != 42 {
+		t.Errorf("comment venue not replaced: venue=%q ccf=%q citations=%d", papers[0].Venue, papers[0].CCFClass, papers[0].Citations)
+	}
+	if papers[1].Venue != "ICCV" {
+		t.Errorf("real venue overwritten: %q", papers[1].Venue)
+	}
+}
+
+func mustParse(t *testing.T, s string) query.Node {
+	t.Helper()
+	n, err := query.Parse(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
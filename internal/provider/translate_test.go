@@ -0,0 +1,88 @@
+package provider
+
+import (
+	"reflect"
+	"testing"
+
+	"paper-scraper/internal/query"
+)
+
+func TestArxivSearchParts(t *testing.T) {
+	tests := []struct {
+		input    string
+		include  []string
+		exclude  []string
+		residual string
+	}{
+		{`diffusion`, []string{"all:diffusion"}, nil, ""},
+		{`author:"Yann LeCun" title:diffusion venue:CVPR year:2023..2025 -survey`,
+			[]string{`au:"Yann LeCun"`, "ti:diffusion", "submittedDate:[202301010000 TO 202512312359]"},
+			[]string{"all:survey"}, "venue:CVPR"},
+		{`(a OR b) AND NOT c`, []string{"(all:a OR all:b)"}, []string{"all:c"}, ""},
+		{`ti:"graph neural" cat:cs.LG`, []string{`ti:"graph neural"`, "cat:cs.LG"}, nil, ""},
+		{`year:..2020`, []string{"submittedDate:[199101010000 TO 202012312359]"}, nil, ""},
+		{`venue:NeurIPS`, nil, nil, "venue:NeurIPS"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			n, err := query.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			include, exclude, residual := arxivSearchParts(n)
+			if !reflect.DeepEqual(include, tt.include) {
+				t.Errorf("include = %q, want %q", include, tt.include)
+			}
+			if !reflect.DeepEqual(exclude, tt.exclude) {
+				t.Errorf("exclude = %q, want %q", exclude, tt.exclude)
+			}
+			if got := nodeString(residual); got != tt.residual {
+				t.Errorf("residual = %s, want %s", got, tt.residual)
+			}
+		})
+	}
+}
+
+func TestOpenAlexSearchParams(t *testing.T) {
+	tests := []struct {
+		input    string
+		search   string
+		filters  []string
+		residual string
+	}{
+		{`diffusion`, "diffusion", nil, ""},
+		{`author:"Yann LeCun" title:diffusion venue:CVPR year:2023..2025 -survey`, "",
+			[]string{"raw_author_name.search:Yann LeCun", "title.search:diffusion", "publication_year:2023-2025"},
+			"(venue:CVPR AND NOT survey)"},
+		{`(a OR b) AND NOT c`, "(a OR b) NOT c", nil, ""},
+		{`a OR b c`, "(a OR (b AND c))", nil, ""},
+		{`year:2021..`, "", []string{"publication_year:>2020"}, ""},
+		{`year:..2020`, "", []string{"publication_year:<2021"}, ""},
+		{`transformer -title:survey`, "transformer", nil, "NOT title:survey"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			n, err := query.Parse(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			search, filters, residual := openAlexSearchParams(n)
+			if search != tt.search {
+				t.Errorf("search = %q, want %q", search, tt.search)
+			}
+			if !reflect.DeepEqual(filters, tt.filters) {
+				t.Errorf("filters = %q, want %q", filters, tt.filters)
+			}
+			if got := nodeString(residual); got != tt.residual {
+				t.Errorf("residual = %s, want %s", got, tt.residual)
+			}
+		})
+	}
+}
+
+func nodeString(n query.Node) string {
+	if n == nil {
+		return ""
+	}
+	return n.String()
+}
@@ -0,0 +1,95 @@
+package query
+
+import (
+	"strconv"
+	"strings"
+
+	"paper-scraper/internal/model"
+)
+
+// Match 判断论文是否满足查询条件，用于数据源无法原生表达某些条件时的后置过滤。
+// 检索词按不区分大小写的子串匹配；nil 查询匹配所有论文
+func Match(n Node, p model.Paper) bool {
+	if n == nil {
+		return true
+	}
+	switch v := n.(type) {
+	case *Term:
+		return matchTerm(v, p)
+	case *YearRange:
+		year := paperYear(p)
+		return year != 0 && v.Contains(year)
+	case *And:
+		for _, c := range v.Children {
+			if !Match(c, p) {
+				return false
+			}
+		}
+		return true
+	case *Or:
+		for _, c := range v.Children {
+			if Match(c, p) {
+				return true
+			}
+		}
+		return false
+	case *Not:
+		return !Match(v.Child, p)
+	}
+	return false
+}
+
+// Filter 返回满足查询条件的论文
+func Filter(n Node, papers []model.Paper) []model.Paper {
+	if n == nil {
+		return papers
+	}
+	var out []model.Paper
+	for _, p := range papers {
+		if Match(n, p) {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func matchTerm(t *Term, p model.Paper) bool {
+	value := strings.ToLower(t.Value)
+	contains := func(s string) bool {
+		return strings.Contains(strings.ToLower(s), value)
+	}
+	anyOf := func(list []string) bool {
+		for _, s := range list {
+			if contains(s) {
+				return true
+			}
+		}
+		return false
+	}
+
+	switch t.Field {
+	case FieldAuthor:
+		return anyOf(p.Authors)
+	case FieldTitle:
+		return contains(p.Title)
+	case FieldAbstract:
+		return contains(p.Abstract)
+	case FieldVenue:
+		return contains(p.Venue)
+	case FieldCategory:
+		return anyOf(p.Categories)
+	}
+	return contains(p.Title) || contains(p.Abstract) || anyOf(p.Authors) || contains(p.Venue) || anyOf(p.Categories)
+}
+
+func paperYear(p model.Paper) int {
+	if p.Year != nil && *p.Year != 0 {
+		return *p.Year
+	}
+	if len(p.PublishedAt) >= 4 {
+		if y, err := strconv.Atoi(p.PublishedAt[:4]); err == nil {
+			return y
+		}
+	}
+	return 0
+}
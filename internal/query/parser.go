@@ -0,0 +1,251 @@
+package query
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokWord tokenKind = iota
+	tokPhrase
+	tokField // 形如 author: 的字段前缀
+	tokLParen
+	tokRParen
+	tokMinus
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+}
+
+// Parse 解析检索表达式，例如：
+//
+//	author:"Yann LeCun" title:diffusion venue:CVPR year:2023..2025 -survey
+//
+// 支持 AND / OR / NOT（大写）、括号分组、"-" 前缀否定与双引号短语；
+// 相邻条件之间默认为 AND。空查询返回 nil
+func Parse(input string) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	p := &parser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.tokens[p.pos].value, p.pos)
+	}
+	return node, nil
+}
+
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokLParen, value: "("})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokRParen, value: ")"})
+			i++
+		case r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != '"' {
+				end++
+			}
+			if end >= len(runes) {
+				return nil, fmt.Errorf("unterminated phrase starting at position %d", i)
+			}
+			tokens = append(tokens, token{kind: tokPhrase, value: string(runes[i+1 : end])})
+			i = end + 1
+		case r == '-' && (i == 0 || unicode.IsSpace(runes[i-1]) || runes[i-1] == '('):
+			tokens = append(tokens, token{kind: tokMinus, value: "-"})
+			i++
+		default:
+			end := i
+			for end < len(runes) && !unicode.IsSpace(runes[end]) && runes[end] != '(' && runes[end] != ')' && runes[end] != '"' {
+				end++
+			}
+			word := string(runes[i:end])
+			i = end
+
+			// 已知字段名后跟冒号视为字段前缀
+			if idx := strings.Index(word, ":"); idx > 0 {
+				if field, ok := fieldAliases[strings.ToLower(word[:idx])]; ok {
+					tokens = append(tokens, token{kind: tokField, value: field})
+					if rest := word[idx+1:]; rest != "" {
+						tokens = append(tokens, token{kind: tokWord, value: rest})
+					}
+					continue
+				}
+			}
+			switch word {
+			case "AND":
+				tokens = append(tokens, token{kind: tokAnd, value: word})
+			case "OR":
+				tokens = append(tokens, token{kind: tokOr, value: word})
+			case "NOT":
+				tokens = append(tokens, token{kind: tokNot, value: word})
+			default:
+				tokens = append(tokens, token{kind: tokWord, value: word})
+			}
+		}
+	}
+	return tokens, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	if p.pos >= len(p.tokens) {
+		return nil
+	}
+	return &p.tokens[p.pos]
+}
+
+func (p *parser) parseOr() (Node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []Node{left}
+	for t := p.peek(); t != nil && t.kind == tokOr; t = p.peek() {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, right)
+	}
+	if len(children) == 1 {
+		return left, nil
+	}
+	return &Or{Children: children}, nil
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	var children []Node
+	for {
+		t := p.peek()
+		if t == nil || t.kind == tokOr || t.kind == tokRParen {
+			break
+		}
+		if t.kind == tokAnd {
+			p.pos++
+			continue
+		}
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, node)
+	}
+	if len(children) == 0 {
+		return nil, fmt.Errorf("expected search term at position %d", p.pos)
+	}
+	return AndOf(children), nil
+}
+
+func (p *parser) parseUnary() (Node, error) {
+	t := p.peek()
+	if t != nil && (t.kind == tokNot || t.kind == tokMinus) {
+		p.pos++
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &Not{Child: child}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of query")
+	}
+	p.pos++
+	switch t.kind {
+	case tokLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if next := p.peek(); next == nil || next.kind != tokRParen {
+			return nil, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return node, nil
+	case tokWord:
+		return &Term{Value: t.value}, nil
+	case tokPhrase:
+		return &Term{Value: t.value, Phrase: true}, nil
+	case tokField:
+		next := p.peek()
+		if next == nil || (next.kind != tokWord && next.kind != tokPhrase) {
+			return nil, fmt.Errorf("missing value for field %q", t.value)
+		}
+		p.pos++
+		if t.value == FieldYear {
+			return parseYearRange(next.value)
+		}
+		return &Term{Field: t.value, Value: next.value, Phrase: next.kind == tokPhrase}, nil
+	}
+	return nil, fmt.Errorf("unexpected %q", t.value)
+}
+
+// parseYearRange 解析 2023、2023..2025、2023..、..2025 形式的年份
+func parseYearRange(value string) (Node, error) {
+	from, to, isRange := strings.Cut(value, "..")
+	parse := func(s string) (int, error) {
+		if s == "" {
+			return 0, nil
+		}
+		y, err := strconv.Atoi(s)
+		if err != nil || y < 1000 || y > 9999 {
+			return 0, fmt.Errorf("invalid year %q", s)
+		}
+		return y, nil
+	}
+	fromY, err := parse(from)
+	if err != nil {
+		return nil, err
+	}
+	if !isRange {
+		if fromY == 0 {
+			return nil, fmt.Errorf("invalid year %q", value)
+		}
+		return &YearRange{From: fromY, To: fromY}, nil
+	}
+	toY, err := parse(to)
+	if err != nil {
+		return nil, err
+	}
+	if fromY == 0 && toY == 0 {
+		return nil, fmt.Errorf("invalid year range %q", value)
+	}
+	if toY != 0 && fromY > toY {
+		return nil, fmt.Errorf("invalid year range %q", value)
+	}
+	return &YearRange{From: fromY, To: toY}, nil
+}
@@ -0,0 +1,98 @@
+package query
+
+import (
+	"testing"
+
+	"paper-scraper/internal/model"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string // 语法树的 String()，为空表示 nil
+	}{
+		{``, ``},
+		{`diffusion`, `diffusion`},
+		{`"graph neural network"`, `"graph neural network"`},
+		{`author:"Yann LeCun" title:diffusion venue:CVPR year:2023..2025 -survey`,
+			`(author:"Yann LeCun" AND title:diffusion AND venue:CVPR AND year:2023..2025 AND NOT survey)`},
+		{`au:hinton ti:capsule abs:routing cat:cs.LG`,
+			`(author:hinton AND title:capsule AND abstract:routing AND category:cs.LG)`},
+		{`a OR b c`, `(a OR (b AND c))`},
+		{`(a OR b) AND NOT c`, `((a OR b) AND NOT c)`},
+		{`(a b) OR c`, `((a AND b) OR c)`},
+		{`year:2024`, `year:2024`},
+		{`year:2021..`, `year:2021..`},
+		{`year:..2020`, `year:..2020`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			n, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.input, err)
+			}
+			got := ""
+			if n != nil {
+				got = n.String()
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, input := range []string{`(a`, `a)`, `author:`, `"open`, `year:abc`, `year:99`} {
+		if n, err := Parse(input); err == nil {
+			t.Errorf("Parse(%q) = %v, want error", input, n)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	year := 2023
+	p := model.Paper{
+		Title:      "Denoising Diffusion Models for Graphs",
+		Abstract:   "We study score-based generative models.",
+		Authors:    []string{"Yann LeCun", "Ada Lovelace"},
+		Venue:      "CVPR",
+		Year:       &year,
+		Categories: []string{"cs.LG"},
+	}
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{`diffusion`, true},
+		{`DIFFUSION`, true},
+		{`transformer`, false},
+		{`author:lecun title:diffusion`, true},
+		{`author:hinton`, false},
+		{`venue:cvpr year:2022..2024`, true},
+		{`year:..2022`, false},
+		{`abstract:"score-based"`, true},
+		{`cat:cs.LG -survey`, true},
+		{`diffusion -graphs`, false},
+		{`transformer OR generative`, true},
+	}
+	for _, tt := range tests {
+		n, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.query, err)
+		}
+		if got := Match(n, p); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestText(t *testing.T) {
+	n, err := Parse(`author:"Yann LeCun" diffusion -survey "score matching"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := Text(n), `"Yann LeCun" diffusion "score matching"`; got != want {
+		t.Errorf("Text = %q, want %q", got, want)
+	}
+}
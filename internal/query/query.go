@@ -0,0 +1,191 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 支持的检索字段
+const (
+	FieldAny      = ""
+	FieldAuthor   = "author"
+	FieldTitle    = "title"
+	FieldAbstract = "abstract"
+	FieldVenue    = "venue"
+	FieldCategory = "category"
+	FieldYear     = "year"
+)
+
+// fieldAliases 将用户输入的字段名映射为规范字段
+var fieldAliases = map[string]string{
+	"author": FieldAuthor, "au": FieldAuthor,
+	"title": FieldTitle, "ti": FieldTitle,
+	"abstract": FieldAbstract, "abs": FieldAbstract,
+	"venue":    FieldVenue,
+	"category": FieldCategory, "cat": FieldCategory,
+	"year": FieldYear,
+}
+
+// Node 是查询语法树的节点
+type Node interface {
+	String() string
+}
+
+// Term 是一个检索词，Field 为空表示在所有字段中匹配
+type Term struct {
+	Field  string
+	Value  string
+	Phrase bool // 用户以双引号给出的短语
+}
+
+// YearRange 是年份范围，From/To 为 0 表示不限
+type YearRange struct {
+	From int
+	To   int
+}
+
+type And struct {
+	Children []Node
+}
+
+type Or struct {
+	Children []Node
+}
+
+type Not struct {
+	Child Node
+}
+
+func (t *Term) String() string {
+	value := t.Value
+	if t.Phrase || strings.ContainsAny(value, " \t") {
+		value = `"` + value + `"`
+	}
+	if t.Field == FieldAny {
+		return value
+	}
+	return t.Field + ":" + value
+}
+
+func (r *YearRange) String() string {
+	switch {
+	case r.From == r.To:
+		return fmt.Sprintf("year:%d", r.From)
+	case r.To == 0:
+		return fmt.Sprintf("year:%d..", r.From)
+	case r.From == 0:
+		return fmt.Sprintf("year:..%d", r.To)
+	}
+	return fmt.Sprintf("year:%d..%d", r.From, r.To)
+}
+
+func (a *And) String() string {
+	parts := make([]string, len(a.Children))
+	for i, c := range a.Children {
+		parts[i] = c.String()
+	}
+	return "(" + strings.Join(parts, " AND ") + ")"
+}
+
+func (o *Or) String() string {
+	parts := make([]string, len(o.Children))
+	for i, c := range o.Children {
+		parts[i] = c.String()
+	}
+	return "(" + strings.Join(parts, " OR ") + ")"
+}
+
+func (n *Not) String() string {
+	return "NOT " + n.Child.String()
+}
+
+// Contains 判断 year 是否落在范围内
+func (r *YearRange) Contains(year int) bool {
+	if r.From != 0 && year < r.From {
+		return false
+	}
+	if r.To != 0 && year > r.To {
+		return false
+	}
+	return true
+}
+
+// Conjuncts 展开顶层的 AND，返回需要同时满足的子条件
+func Conjuncts(n Node) []Node {
+	if n == nil {
+		return nil
+	}
+	if a, ok := n.(*And); ok {
+		var out []Node
+		for _, c := range a.Children {
+			out = append(out, Conjuncts(c)...)
+		}
+		return out
+	}
+	return []Node{n}
+}
+
+// AndOf 将多个条件组合为 AND，空列表返回 nil
+func AndOf(nodes []Node) Node {
+	switch len(nodes) {
+	case 0:
+		return nil
+	case 1:
+		return nodes[0]
+	}
+	return &And{Children: nodes}
+}
+
+// Text 提取语法树中所有非否定检索词的取值（短语保留双引号），
+// 供只支持关键词检索的数据源与相关度排序使用
+func Text(n Node) string {
+	var parts []string
+	var walk func(Node)
+	walk = func(n Node) {
+		switch v := n.(type) {
+		case *Term:
+			if v.Phrase || strings.Contains(v.Value, " ") {
+				parts = append(parts, `"`+v.Value+`"`)
+			} else {
+				parts = append(parts, v.Value)
+			}
+		case *And:
+			for _, c := range v.Children {
+				walk(c)
+			}
+		case *Or:
+			for _, c := range v.Children {
+				walk(c)
+			}
+		}
+	}
+	if n != nil {
+		walk(n)
+	}
+	return strings.Join(parts, " ")
+}
+
+// IsFreeText 判断子树是否只包含不限字段的检索词
+func IsFreeText(n Node) bool {
+	switch v := n.(type) {
+	case *Term:
+		return v.Field == FieldAny
+	case *And:
+		for _, c := range v.Children {
+			if !IsFreeText(c) {
+				return false
+			}
+		}
+		return true
+	case *Or:
+		for _, c := range v.Children {
+			if !IsFreeText(c) {
+				return false
+			}
+		}
+		return true
+	case *Not:
+		return IsFreeText(v.Child)
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+// Package scheduler 按类 cron 的时间表在后台运行任务（论文摄取、引用量刷新、每日摘要预计算等），
+// 并记录每个任务的运行状态，供管理接口查看
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+)
+
+// DefaultTimeout 是单次任务运行的默认时限
+const DefaultTimeout = 10 * time.Minute
+
+// Off 作为时间表时表示禁用该任务
+const Off = "off"
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrRunning    = errors.New("job is already running")
+)
+
+// JobFunc 是任务的执行函数，返回一句结果说明（如“摄取 120 篇”）；ctx 在超时或调度器停止时取消
+type JobFunc func(ctx context.Context) (string, error)
+
+// Status 是任务的运行状态
+type Status struct {
+	Name       string     `json:"name"`
+	Schedule   string     `json:"schedule"`
+	Running    bool       `json:"running"`
+	NextRun    *time.Time `json:"next_run,omitempty"`
+	LastRun    *time.Time `json:"last_run,omitempty"`
+	LastMs     int64      `json:"last_duration_ms"`
+	LastResult string     `json:"last_result,omitempty"`
+	LastError  string     `json:"last_error,omitempty"`
+	Runs       int        `json:"runs"`
+	Failures   int        `json:"failures"`
+}
+
+type job struct {
+	name     string
+	schedule string
+	fn       JobFunc
+	entry    cron.EntryID
+	status   Status
+}
+
+// Scheduler 管理一组定时任务。同一任务上一次运行未结束时跳过本次触发
+type Scheduler struct {
+	cron    *cron.Cron
+	timeout time.Duration
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+// New 创建调度器，timeout 为单次任务运行的时限，非正值使用 DefaultTimeout
+func New(timeout time.Duration) *Scheduler {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Scheduler{
+		cron:    cron.New(),
+		timeout: timeout,
+		ctx:     ctx,
+		cancel:  cancel,
+		jobs:    make(map[string]*job),
+	}
+}
+
+// Add 注册任务。schedule 为标准 5 字段 cron 表达式或 @every 1h、@daily 等写法；
+// 为 "off" 时任务只能通过 Run 手动触发
+func (s *Scheduler) Add(name, schedule string, fn JobFunc) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("job %q already registered", name)
+	}
+	j := &job{name: name, schedule: schedule, fn: fn, status: Status{Name: name, Schedule: schedule}}
+	if schedule != Off {
+		id, err := s.cron.AddFunc(schedule, func() { s.run(j) })
+		if err != nil {
+			return fmt.Errorf("job %q: %w", name, err)
+		}
+		j.entry = id
+	}
+	s.jobs[name] = j
+	return nil
+}
+
+// Start 开始按时间表运行任务
+func (s *Scheduler) Start() {
+	s.cron.Start()
+}
+
+// Stop 停止调度，取消正在运行的任务并等待其退出。
+// 先取消任务的 ctx：cron 的 Done 要等正在运行的任务返回后才关闭
+func (s *Scheduler) Stop() {
+	s.cancel()
+	<-s.cron.Stop().Done()
+	s.wg.Wait()
+}
+
+// Run 立即在后台运行一次任务
+func (s *Scheduler) Run(name string) error {
+	s.mu.Lock()
+	j, ok := s.jobs[name]
+	running := ok && j.status.Running
+	s.mu.Unlock()
+	if !ok {
+		return ErrUnknownJob
+	}
+	if running {
+		return ErrRunning
+	}
+	go s.run(j)
+	return nil
+}
+
+func (s *Scheduler) run(j *job) {
+	s.mu.Lock()
+	if j.status.Running || s.ctx.Err() != nil {
+		s.mu.Unlock()
+		return
+	}
+	j.status.Running = true
+	s.wg.Add(1)
+	s.mu.Unlock()
+	defer s.wg.Done()
+
+	ctx, cancel := context.WithTimeout(s.ctx, s.timeout)
+	defer cancel()
+	start := time.Now()
+	result, err := j.fn(ctx)
+	if err != nil {
+		fmt.Printf("job %s error: %v\n", j.name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j.status.Running = false
+	j.status.LastRun = &start
+	j.status.LastMs = time.Since(start).Milliseconds()
+	j.status.Runs++
+	j.status.LastResult = result
+	j.status.LastError = ""
+	if err != nil {
+		j.status.Failures++
+		j.status.LastError = err.Error()
+	}
+}
+
+// Status 返回全部任务的运行状态（按名称排序）
+func (s *Scheduler) Status() []Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	statuses := make([]Status, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		st := j.status
+		if j.entry != 0 {
+			if next := s.cron.Entry(j.entry).Next; !next.IsZero() {
+				st.NextRun = &next
+			}
+		}
+		statuses = append(statuses, st)
+	}
+	sort.Slice(statuses, func(i, k int) bool { return statuses[i].Name < statuses[k].Name })
+	return statuses
+}
+
+// ParseSchedules 解析 "harvest=@every 1h;summaries=*/30 * * * *" 形式的时间表配置。
+// cron 表达式中含有逗号与空格，因此以分号分隔
+func ParseSchedules(spec string) (map[string]string, error) {
+	schedules := make(map[string]string)
+	for _, part := range strings.Split(spec, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, schedule, ok := strings.Cut(part, "=")
+		name, schedule = strings.TrimSpace(name), strings.TrimSpace(schedule)
+		if !ok || name == "" || schedule == "" {
+			return nil, fmt.Errorf("invalid schedule %q", part)
+		}
+		if schedule != Off {
+			if _, err := cron.ParseStandard(schedule); err != nil {
+				return nil, fmt.Errorf("schedule for %s: %w", name, err)
+			}
+		}
+		schedules[name] = schedule
+	}
+	return schedules, nil
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestStopCancelsRunningJob(t *testing.T) {
+	s := New(time.Hour)
+	started := make(chan struct{})
+	if err := s.Add("block", "@every 1h", func(ctx context.Context) (string, error) {
+		close(started)
+		<-ctx.Done()
+		return "", ctx.Err()
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s.Start()
+	if err := s.Run("block"); err != nil {
+		t.Fatal(err)
+	}
+	<-started
+
+	stopped := make(chan struct{})
+	go func() {
+		s.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stop did not return while a job was running")
+	}
+
+	st := s.Status()[0]
+	if st.Running || st.Failures != 1 || st.LastError != context.Canceled.Error() {
+		t.Errorf("status after Stop = %+v, want one canceled run", st)
+	}
+}
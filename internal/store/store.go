@@ -0,0 +1,530 @@
+package store
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"paper-scraper/internal/merge"
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	bucketPapers    = []byte("papers")    // 规范 ID -> Record
+	bucketAliases   = []byte("aliases")   // 任意标识（arxiv:/doi:/id:）-> 规范 ID
+	bucketMeta      = []byte("meta")      // 摄取时间等元数据
+	bucketSummaries = []byte("summaries") // 范围名称/日期 -> 每日摘要（JSON）
+	bucketByDate    = []byte("published") // 发布日期（UTC，YYYY-MM-DD）|规范 ID -> 空，按日期范围检索时使用
+)
+
+// metaDateIndex 记录 published 索引已由全部现有记录建立
+const metaDateIndex = "index:published"
+
+// Record 是存储中的一条论文记录
+type Record struct {
+	Key       string      `json:"key"`
+	Paper     model.Paper `json:"paper"`
+	FirstSeen time.Time   `json:"first_seen"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Store 是基于 bbolt 的嵌入式论文库，无需外部数据库服务
+type Store struct {
+	db *bolt.DB
+
+	mu        sync.RWMutex
+	listeners []func(Record)
+}
+
+// Open 打开（必要时创建）位于 path 的数据库文件
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{bucketPapers, bucketAliases, bucketMeta, bucketSummaries, bucketByDate} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return buildDateIndex(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	return &Store{db: db}, nil
+}
+
+// buildDateIndex 为建立索引之前写入的记录补建 published 索引，只在首次打开旧数据库时执行
+func buildDateIndex(tx *bolt.Tx) error {
+	meta := tx.Bucket(bucketMeta)
+	if meta.Get([]byte(metaDateIndex)) != nil {
+		return nil
+	}
+	db := tx.Bucket(bucketByDate)
+	err := tx.Bucket(bucketPapers).ForEach(func(k, v []byte) error {
+		var rec Record
+		if err := json.Unmarshal(v, &rec); err != nil {
+			return fmt.Errorf("decode %s: %w", k, err)
+		}
+		if key := dateKey(rec.Paper, rec.Key); key != nil {
+			return db.Put(key, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return meta.Put([]byte(metaDateIndex), []byte(time.Now().UTC().Format(time.RFC3339)))
+}
+
+// dateKey 返回论文在 published 索引中的键，没有可解析的发布日期时返回 nil
+func dateKey(p model.Paper, key string) []byte {
+	t := provider.ParseDate(p.PublishedAt)
+	if t.IsZero() {
+		return nil
+	}
+	return []byte(t.UTC().Format("2006-01-02") + "|" + key)
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// OnUpsert 注册回调，在每条记录写入成功后调用（例如用于同步本地检索索引）
+func (s *Store) OnUpsert(fn func(Record)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+// CanonicalID 计算论文的规范 ID：优先 arXiv ID，其次 DOI，最后使用来源内部 ID
+func CanonicalID(p model.Paper) string {
+	if id := p.ArxivID; id != "" {
+		return "arxiv:" + id
+	}
+	if id := provider.ExtractArxivID(p.ID); id != "" {
+		return "arxiv:" + id
+	}
+	if doi := provider.NormalizeDOI(p.DOI); doi != "" {
+		if id := provider.ExtractArxivID(doi); id != "" {
+			return "arxiv:" + id
+		}
+		return "doi:" + doi
+	}
+	return "id:" + p.ID
+}
+
+// aliasesOf 返回论文的全部可查找标识
+func aliasesOf(p model.Paper) []string {
+	var aliases []string
+	if p.ArxivID != "" {
+		aliases = append(aliases, "arxiv:"+p.ArxivID)
+	}
+	if id := provider.ExtractArxivID(p.ID); id != "" {
+		aliases = append(aliases, "arxiv:"+id)
+	}
+	if doi := provider.NormalizeDOI(p.DOI); doi != "" {
+		aliases = append(aliases, "doi:"+doi)
+		if id := provider.ExtractArxivID(doi); id != "" {
+			aliases = append(aliases, "arxiv:"+id)
+		}
+	}
+	if p.ID != "" {
+		aliases = append(aliases, "id:"+p.ID)
+	}
+	return aliases
+}
+
+// UpsertResult 汇总一次写入的新增与更新数量
+type UpsertResult struct {
+	Added   int
+	Updated int
+}
+
+// Upsert 写入论文。已存在的记录（按任一标识匹配）与新记录融合，
+// 以刷新引用量、venue 等随时间变化的字段
+func (s *Store) Upsert(papers []model.Paper) (UpsertResult, error) {
+	var result UpsertResult
+	if len(papers) == 0 {
+		return result, nil
+	}
+	now := time.Now().UTC()
+
+	var written []Record
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		pb := tx.Bucket(bucketPapers)
+		ab := tx.Bucket(bucketAliases)
+		db := tx.Bucket(bucketByDate)
+
+		for _, p := range papers {
+			aliases := aliasesOf(p)
+
+			// 任一标识已存在即视为同一篇论文
+			var key string
+			for _, alias := range aliases {
+				if v := ab.Get([]byte(alias)); v != nil {
+					key = string(v)
+					break
+				}
+			}
+			if key == "" && pb.Get([]byte(CanonicalID(p))) != nil {
+				key = CanonicalID(p)
+			}
+
+			rec := Record{FirstSeen: now}
+			if key != "" {
+				if raw := pb.Get([]byte(key)); raw != nil {
+					if err := json.Unmarshal(raw, &rec); err != nil {
+						return fmt.Errorf("decode %s: %w", key, err)
+					}
+				}
+			}
+
+			if rec.Key == "" {
+				rec.Key = CanonicalID(p)
+				rec.Paper = p
+				if len(rec.Paper.Sources) == 0 {
+					rec.Paper.Sources = []string{p.Source}
+				}
+				result.Added++
+			} else {
+				// 融合后发布日期可能变化，先移除旧的索引键
+				if old := dateKey(rec.Paper, rec.Key); old != nil {
+					if err := db.Delete(old); err != nil {
+						return err
+					}
+				}
+				// 新记录放在前面，使其引用量与 venue 优先参与融合
+				rec.Paper = merge.Fuse([]model.Paper{p, rec.Paper})
+				result.Updated++
+			}
+			rec.UpdatedAt = now
+
+			raw, err := json.Marshal(rec)
+			if err != nil {
+				return err
+			}
+			if err := pb.Put([]byte(rec.Key), raw); err != nil {
+				return err
+			}
+			if key := dateKey(rec.Paper, rec.Key); key != nil {
+				if err := db.Put(key, nil); err != nil {
+					return err
+				}
+			}
+			for _, alias := range append(aliases, aliasesOf(rec.Paper)...) {
+				if err := ab.Put([]byte(alias), []byte(rec.Key)); err != nil {
+					return err
+				}
+			}
+			written = append(written, rec)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+	for _, rec := range written {
+		for _, fn := range listeners {
+			fn(rec)
+		}
+	}
+	return result, nil
+}
+
+// Get 按任意标识查找论文：规范 ID、arXiv ID、DOI 或来源内部 ID
+func (s *Store) Get(id string) (Record, bool, error) {
+	var rec Record
+	found := false
+	err := s.db.View(func(tx *bolt.Tx) error {
+		key := s.resolve(tx, id)
+		if key == "" {
+			return nil
+		}
+		raw := tx.Bucket(bucketPapers).Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+		found = true
+		return json.Unmarshal(raw, &rec)
+	})
+	return rec, found, err
+}
+
+func (s *Store) resolve(tx *bolt.Tx, id string) string {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return ""
+	}
+	candidates := []string{id, "id:" + id}
+	if arxivID := provider.ExtractArxivID(id); arxivID != "" {
+		candidates = append(candidates, "arxiv:"+arxivID)
+	} else if !strings.Contains(id, ":") {
+		// 裸 arXiv ID，例如 2106.12345
+		candidates = append(candidates, "arxiv:"+id)
+	}
+	if strings.HasPrefix(strings.ToLower(id), "10.") || strings.Contains(strings.ToLower(id), "doi.org/") {
+		candidates = append(candidates, "doi:"+provider.NormalizeDOI(id))
+	}
+
+	pb := tx.Bucket(bucketPapers)
+	ab := tx.Bucket(bucketAliases)
+	for _, c := range candidates {
+		if pb.Get([]byte(c)) != nil {
+			return c
+		}
+		if v := ab.Get([]byte(c)); v != nil {
+			return string(v)
+		}
+	}
+	return ""
+}
+
+// Filter 描述对本地论文库的检索条件
+type Filter struct {
+	Text      string   // 所有词都需出现在标题/摘要/作者/venue 中
+	Sources   []string // 仅返回包含这些来源的记录，为空表示不限
+	StartDate string   // YYYY-MM-DD
+	EndDate   string   // YYYY-MM-DD
+	Sort      string   // published_desc / published_asc / citations
+	Limit     int
+	Offset    int
+	Match     func(model.Paper) bool // 额外的匹配条件，为 nil 表示不限
+}
+
+// Search 扫描全部记录并返回满足条件的论文
+func (s *Store) Search(f Filter) ([]model.Paper, error) {
+	var matched []model.Paper
+	err := s.Scan(f, func(p model.Paper) error {
+		matched = append(matched, p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if f.Sort == "published_asc" {
+			return matched[i].PublishedAt < matched[j].PublishedAt
+		}
+		if f.Sort == "citations" && matched[i].Citations != matched[j].Citations {
+			return matched[i].Citations > matched[j].Citations
+		}
+		return matched[i].PublishedAt > matched[j].PublishedAt
+	})
+
+	if f.Offset > 0 {
+		if f.Offset >= len(matched) {
+			return nil, nil
+		}
+		matched = matched[f.Offset:]
+	}
+	if f.Limit > 0 && len(matched) > f.Limit {
+		matched = matched[:f.Limit]
+	}
+	return matched, nil
+}
+
+// Scan 依次对满足 f 中过滤条件的论文调用 fn，不排序也不分页（忽略 Sort、Limit 与 Offset），
+// 适合在不保留论文本身的情况下做统计。指定日期范围时只读取 published 索引中该范围内的记录，
+// 否则遍历全部记录。fn 返回错误时中止遍历
+func (s *Store) Scan(f Filter, fn func(model.Paper) error) error {
+	terms := strings.Fields(strings.ToLower(f.Text))
+	var startT, endT time.Time
+	if f.StartDate != "" {
+		startT = provider.ParseDate(f.StartDate)
+	}
+	if f.EndDate != "" {
+		endT = provider.ParseDate(f.EndDate)
+		if !endT.IsZero() {
+			endT = endT.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+
+	visit := s.ForEach
+	if !startT.IsZero() || !endT.IsZero() {
+		visit = func(fn func(Record) error) error {
+			return s.forEachPublished(startT, endT, fn)
+		}
+	}
+	return visit(func(rec Record) error {
+		p := rec.Paper
+		if len(f.Sources) > 0 && !hasAnySource(p, f.Sources) {
+			return nil
+		}
+		if !startT.IsZero() || !endT.IsZero() {
+			pubT := provider.ParseDate(p.PublishedAt)
+			if pubT.IsZero() {
+				return nil
+			}
+			if (!startT.IsZero() && pubT.Before(startT)) || (!endT.IsZero() && pubT.After(endT)) {
+				return nil
+			}
+		}
+		if f.Match != nil && !f.Match(p) {
+			return nil
+		}
+		if len(terms) > 0 {
+			haystack := strings.ToLower(p.Title + " " + p.Abstract + " " + strings.Join(p.Authors, " ") + " " + p.Venue)
+			for _, t := range terms {
+				if !strings.Contains(haystack, t) {
+					return nil
+				}
+			}
+		}
+		return fn(p)
+	})
+}
+
+// ForEach 依次遍历所有记录，fn 返回错误时中止遍历
+func (s *Store) ForEach(fn func(Record) error) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketPapers).ForEach(func(k, v []byte) error {
+			var rec Record
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return fmt.Errorf("decode %s: %w", k, err)
+			}
+			return fn(rec)
+		})
+	})
+}
+
+// forEachPublished 按发布日期升序遍历 UTC 日期在 [from, to] 内的记录，零值表示不限
+func (s *Store) forEachPublished(from, to time.Time, fn func(Record) error) error {
+	var start []byte
+	if !from.IsZero() {
+		start = []byte(from.UTC().Format("2006-01-02"))
+	}
+	end := "\xff"
+	if !to.IsZero() {
+		end = to.UTC().Format("2006-01-02") + "|\xff"
+	}
+	return s.db.View(func(tx *bolt.Tx) error {
+		pb := tx.Bucket(bucketPapers)
+		c := tx.Bucket(bucketByDate).Cursor()
+		k, _ := c.First()
+		if start != nil {
+			k, _ = c.Seek(start)
+		}
+		for ; k != nil && string(k) <= end; k, _ = c.Next() {
+			_, key, ok := strings.Cut(string(k), "|")
+			if !ok {
+				continue
+			}
+			v := pb.Get([]byte(key))
+			if v == nil {
+				continue
+			}
+			var rec Record
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return fmt.Errorf("decode %s: %w", key, err)
+			}
+			if err := fn(rec); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Count 返回存储的论文数量
+func (s *Store) Count() int {
+	n := 0
+	s.db.View(func(tx *bolt.Tx) error {
+		n = tx.Bucket(bucketPapers).Stats().KeyN
+		return nil
+	})
+	return n
+}
+
+// MarkIngested 记录某个摄取任务（例如某天的每日摘要数据）的完成时间
+func (s *Store) MarkIngested(key string, t time.Time) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketMeta).Put([]byte("ingested:"+key), []byte(t.UTC().Format(time.RFC3339)))
+	})
+}
+
+// LastIngested 返回某个摄取任务最近一次完成的时间，未摄取过时返回零值
+func (s *Store) LastIngested(key string) time.Time {
+	var t time.Time
+	s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(bucketMeta).Get([]byte("ingested:" + key)); v != nil {
+			t, _ = time.Parse(time.RFC3339, string(v))
+		}
+		return nil
+	})
+	return t
+}
+
+// summaryKey 以 "范围名称/YYYY-MM-DD" 作为键，同一范围的摘要按日期有序排列
+func summaryKey(profile, date string) []byte {
+	return []byte(profile + "/" + date)
+}
+
+// PutSummary 保存某个摘要范围在某一天生成的每日摘要（JSON），已存在时覆盖
+func (s *Store) PutSummary(profile, date string, data []byte) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketSummaries).Put(summaryKey(profile, date), data)
+	})
+}
+
+// GetSummary 读取某个摘要范围某一天的每日摘要
+func (s *Store) GetSummary(profile, date string) ([]byte, bool, error) {
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(bucketSummaries).Get(summaryKey(profile, date)); v != nil {
+			data = append([]byte(nil), v...)
+		}
+		return nil
+	})
+	return data, data != nil, err
+}
+
+// ForEachSummary 按日期升序遍历某个摘要范围在 [from, to] 内保存的每日摘要，fn 返回错误时中止遍历
+func (s *Store) ForEachSummary(profile, from, to string, fn func(date string, data []byte) error) error {
+	prefix := []byte(profile + "/")
+	end := summaryKey(profile, to)
+	return s.db.View(func(tx *bolt.Tx) error {
+		c := tx.Bucket(bucketSummaries).Cursor()
+		for k, v := c.Seek(summaryKey(profile, from)); k != nil && bytes.HasPrefix(k, prefix) && bytes.Compare(k, end) <= 0; k, v = c.Next() {
+			if err := fn(string(k[len(prefix):]), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func hasAnySource(p model.Paper, sources []string) bool {
+	all := p.Sources
+	if len(all) == 0 {
+		all = []string{p.Source}
+	}
+	for _, want := range sources {
+		for _, have := range all {
+			if strings.EqualFold(want, have) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,108 @@
+package store
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"paper-scraper/internal/model"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+func openTestStore(t *testing.T, path string) *Store {
+	t.Helper()
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func scanIDs(t *testing.T, s *Store, f Filter) []string {
+	t.Helper()
+	var ids []string
+	if err := s.Scan(f, func(p model.Paper) error {
+		ids = append(ids, p.ArxivID)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestScanDateRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "papers.db")
+	s := openTestStore(t, path)
+	defer s.Close()
+	if _, err := s.Upsert([]model.Paper{
+		{Source: "arxiv", ArxivID: "2401.00001", PublishedAt: "2024-01-01T10:00:00Z"},
+		{Source: "arxiv", ArxivID: "2401.00002", PublishedAt: "2024-01-02"},
+		// UTC 时间为 2024-01-03T02:00:00Z
+		{Source: "arxiv", ArxivID: "2401.00003", PublishedAt: "2024-01-02T21:00:00-05:00"},
+		{Source: "arxiv", ArxivID: "2401.00004", PublishedAt: "2024-01-05"},
+		{Source: "dblp", ArxivID: "2401.00005"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"single day", Filter{StartDate: "2024-01-02", EndDate: "2024-01-02"}, []string{"2401.00002"}},
+		{"utc day", Filter{StartDate: "2024-01-03", EndDate: "2024-01-04"}, []string{"2401.00003"}},
+		{"open start", Filter{EndDate: "2024-01-02"}, []string{"2401.00001", "2401.00002"}},
+		{"open end", Filter{StartDate: "2024-01-03"}, []string{"2401.00003", "2401.00004"}},
+		{"empty range", Filter{StartDate: "2024-02-01", EndDate: "2024-02-28"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scanIDs(t, s, tt.filter); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Scan = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if got := scanIDs(t, s, Filter{}); len(got) != 5 {
+		t.Errorf("Scan without dates returned %d papers, want 5", len(got))
+	}
+
+	// 融合后的发布日期变化时，索引只保留新日期
+	if _, err := s.Upsert([]model.Paper{{Source: "arxiv", ArxivID: "2401.00004", PublishedAt: "2023-12-31"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := scanIDs(t, s, Filter{StartDate: "2024-01-05", EndDate: "2024-01-05"}); got != nil {
+		t.Errorf("stale index entry: Scan = %v", got)
+	}
+	if got := scanIDs(t, s, Filter{EndDate: "2023-12-31"}); !reflect.DeepEqual(got, []string{"2401.00004"}) {
+		t.Errorf("Scan = %v, want [2401.00004]", got)
+	}
+}
+
+func TestOpenBuildsDateIndex(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "papers.db")
+	s := openTestStore(t, path)
+	if _, err := s.Upsert([]model.Paper{
+		{Source: "arxiv", ArxivID: "2401.00001", PublishedAt: "2024-01-01"},
+		{Source: "arxiv", ArxivID: "2401.00002", PublishedAt: "2024-01-02"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟建立索引之前的旧数据库
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.DeleteBucket(bucketByDate); err != nil {
+			return err
+		}
+		return tx.Bucket(bucketMeta).Delete([]byte(metaDateIndex))
+	}); err != nil {
+		t.Fatal(err)
+	}
+	s.Close()
+
+	s = openTestStore(t, path)
+	defer s.Close()
+	if got := scanIDs(t, s, Filter{StartDate: "2024-01-02", EndDate: "2024-01-02"}); !reflect.DeepEqual(got, []string{"2401.00002"}) {
+		t.Errorf("Scan after reopening = %v, want [2401.00002]", got)
+	}
+}
@@ -0,0 +1,210 @@
+// Package summary 描述每日摘要的范围配置。每个命名的 Profile 给出一组
+// arXiv 分类、OpenAlex concept、关键词、回溯窗口与样本量，
+// /daily-summary?profile=systems 按对应的 Profile 生成各自的摘要
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"paper-scraper/internal/model"
+	"paper-scraper/internal/provider"
+	"paper-scraper/internal/query"
+)
+
+// 未配置时的默认值，与最初写死的每日摘要一致
+const (
+	DefaultWindowDays = 2
+	DefaultSampleSize = 100
+)
+
+var defaultSources = []string{"arxiv", "openalex"}
+
+// Profile 是一个每日摘要的范围
+type Profile struct {
+	Name       string   `json:"-"`
+	Categories []string `json:"categories,omitempty"`  // arXiv 分类，如 cs.DC、cs.CR，满足其一即可
+	Concepts   []string `json:"concepts,omitempty"`    // OpenAlex concept ID，满足其一即可
+	Keywords   []string `json:"keywords,omitempty"`    // 关键词（支持检索语法），满足其一即可
+	WindowDays int      `json:"window_days,omitempty"` // 回溯天数，含当天
+	SampleSize int      `json:"sample_size,omitempty"` // 每个数据源拉取的论文数
+	Sources    []string `json:"sources,omitempty"`
+
+	expr query.Node // 关键词解析后的检索表达式，normalize 时生成
+}
+
+// Config 是全部摘要范围，Default 为未指定 profile 时使用的名称
+type Config struct {
+	Default  string              `json:"default"`
+	Profiles map[string]*Profile `json:"profiles"`
+	// Institutions 为摘要中高亮的重点机构（名称片段或 ROR ID），为空时使用内置列表
+	Institutions []string `json:"institutions,omitempty"`
+}
+
+// DefaultConfig 返回内置配置：默认的 ai 与面向系统、安全方向的 systems、security
+func DefaultConfig() *Config {
+	cfg := &Config{
+		Default: "ai",
+		Profiles: map[string]*Profile{
+			"ai": {
+				Keywords: []string{"Artificial Intelligence"},
+			},
+			"systems": {
+				Categories: []string{"cs.DC", "cs.OS", "cs.AR", "cs.NI", "cs.PF"},
+				Concepts:   []string{"C120314980", "C111919701"}, // Distributed computing, Operating system
+			},
+			"security": {
+				Categories: []string{"cs.CR"},
+				Concepts:   []string{"C38652104"}, // Computer security
+			},
+		},
+	}
+	cfg.normalize() // 内置关键词均可解析
+	return cfg
+}
+
+// Load 读取 JSON 配置文件，例如：
+//
+//	{"default": "systems", "profiles": {"systems": {"categories": ["cs.DC", "cs.CR"], "window_days": 3}}}
+func Load(path string) (*Config, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(raw, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if len(cfg.Profiles) == 0 {
+		return nil, fmt.Errorf("%s: no profiles defined", path)
+	}
+	if cfg.Default == "" {
+		if len(cfg.Profiles) > 1 {
+			return nil, fmt.Errorf("%s: default profile is required", path)
+		}
+		for name := range cfg.Profiles {
+			cfg.Default = name
+		}
+	}
+	if _, ok := cfg.Profiles[cfg.Default]; !ok {
+		return nil, fmt.Errorf("%s: default profile %q is not defined", path, cfg.Default)
+	}
+	for name, p := range cfg.Profiles {
+		if p == nil {
+			return nil, fmt.Errorf("%s: profile %q is empty", path, name)
+		}
+	}
+	if err := cfg.normalize(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// normalize 填充名称与未配置的默认值，并解析各 Profile 的关键词
+func (c *Config) normalize() error {
+	for name, p := range c.Profiles {
+		expr, err := parseKeywords(p.Keywords)
+		if err != nil {
+			return fmt.Errorf("profile %q: %w", name, err)
+		}
+		p.expr = expr
+		p.Name = name
+		if p.WindowDays <= 0 {
+			p.WindowDays = DefaultWindowDays
+		}
+		if p.SampleSize <= 0 {
+			p.SampleSize = DefaultSampleSize
+		}
+		if len(p.Sources) == 0 {
+			p.Sources = defaultSources
+		}
+	}
+	return nil
+}
+
+// Profile 按名称查找，name 为空时返回默认 Profile
+func (c *Config) Profile(name string) (*Profile, bool) {
+	if name == "" {
+		name = c.Default
+	}
+	p, ok := c.Profiles[name]
+	return p, ok
+}
+
+// Names 返回全部 profile 名称（按字母序）
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// parseKeywords 将关键词组合为 OR 检索表达式，没有关键词时返回 nil
+func parseKeywords(keywords []string) (query.Node, error) {
+	var children []query.Node
+	for _, k := range keywords {
+		if strings.TrimSpace(k) == "" {
+			continue
+		}
+		n, err := query.Parse(k)
+		if err != nil {
+			return nil, fmt.Errorf("keyword %q: %w", k, err)
+		}
+		if n != nil {
+			children = append(children, n)
+		}
+	}
+	switch len(children) {
+	case 0:
+		return nil, nil
+	case 1:
+		return children[0], nil
+	}
+	return &query.Or{Children: children}, nil
+}
+
+// Window 返回以 now 所在日期结尾、长度为 WindowDays 的日期范围（YYYY-MM-DD）
+func (p *Profile) Window(now time.Time) (startDate, endDate string) {
+	return now.AddDate(0, 0, -(p.WindowDays - 1)).Format("2006-01-02"), now.Format("2006-01-02")
+}
+
+// Query 生成拉取 [startDate, endDate] 内论文的数据源查询，按发布时间降序
+func (p *Profile) Query(startDate, endDate string) provider.Query {
+	return provider.Query{
+		Text:       query.Text(p.expr),
+		Expr:       p.expr,
+		Limit:      p.SampleSize,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Sort:       "published_desc",
+		Categories: p.Categories,
+		Concepts:   p.Concepts,
+	}
+}
+
+// Matches 判断本地论文库中的论文是否属于该范围。
+// 本地记录不保存 OpenAlex concept，只按关键词与 arXiv 分类判断（非 arXiv 记录不检查分类）
+func (p *Profile) Matches(paper model.Paper) bool {
+	if !query.Match(p.expr, paper) {
+		return false
+	}
+	if len(p.Categories) > 0 && hasArxivCategory(paper) {
+		return provider.MatchesCategories(paper, p.Categories)
+	}
+	return true
+}
+
+func hasArxivCategory(paper model.Paper) bool {
+	for _, c := range paper.Categories {
+		if strings.HasPrefix(c, "cs.") {
+			return true
+		}
+	}
+	return false
+}
@@ -1,14 +1,157 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
 	"paper-scraper/internal/api"
+	"paper-scraper/internal/cache"
+	"paper-scraper/internal/index"
+	"paper-scraper/internal/provider"
+	"paper-scraper/internal/scheduler"
+	"paper-scraper/internal/store"
+	"paper-scraper/internal/summary"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	// 本地论文库（嵌入式 bbolt 文件），路径可通过 SCHOLARX_DB 覆盖
+	dbPath := os.Getenv("SCHOLARX_DB")
+	if dbPath == "" {
+		dbPath = "data/scholarx.db"
+	}
+	paperStore, err := store.Open(dbPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	api.SetStore(paperStore)
+
+	// 基于本地论文库构建全文索引，并随新摄取的论文增量更新，作为 sources=local 数据源
+	localIndex := index.New()
+	if err := paperStore.ForEach(func(rec store.Record) error {
+		localIndex.Add(rec.Key, rec.Paper)
+		return nil
+	}); err != nil {
+		log.Fatal(err)
+	}
+	paperStore.OnUpsert(func(rec store.Record) {
+		localIndex.Add(rec.Key, rec.Paper)
+	})
+	provider.Register(index.NewProvider(localIndex))
+
+	// 共用 HTTP 客户端：User-Agent 与联系邮箱（OpenAlex polite pool）统一配置
+	provider.Configure(provider.ClientConfig{
+		UserAgent: os.Getenv("SCHOLARX_USER_AGENT"),
+		Mailto:    os.Getenv("SCHOLARX_MAILTO"),
+	})
+	// Semantic Scholar：API key 与接口地址（可指向本地替身服务）
+	provider.ConfigureS2(os.Getenv("SCHOLARX_S2_BASE_URL"), os.Getenv("SCHOLARX_S2_API_KEY"))
+
+	// 一次请求等待各数据源的总时限，超时后返回已完成的数据源，例如 SCHOLARX_REQUEST_TIMEOUT=10s
+	if v := os.Getenv("SCHOLARX_REQUEST_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			log.Fatal(err)
+		}
+		api.SetRequestDeadline(d)
+	}
+
+	// arXiv OAI-PMH 全量摄取的接口地址；设为 off 时 harvest 任务改用检索接口抽样
+	if v := os.Getenv("SCHOLARX_ARXIV_OAI_URL"); v == "off" {
+		api.SetArxivHarvest(false)
+	} else {
+		provider.ConfigureArxivOAI(v)
+	}
+
+	// 每日摘要的范围配置（JSON），未设置时使用内置的 ai / systems / security
+	if path := os.Getenv("SCHOLARX_SUMMARY_CONFIG"); path != "" {
+		cfg, err := summary.Load(path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		api.SetSummaryConfig(cfg)
+	}
+
+	// 上游数据源的响应缓存：内存 LRU + 可选磁盘层（SCHOLARX_CACHE_DIR），
+	// 各数据源的有效期可通过 SCHOLARX_CACHE_TTL 覆盖，例如 "arxiv=15m,dblp=12h"
+	cacheSize, _ := strconv.Atoi(os.Getenv("SCHOLARX_CACHE_SIZE"))
+	responseCache, err := cache.New(cacheSize, os.Getenv("SCHOLARX_CACHE_DIR"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	// 磁盘层的保留时长与总大小上限，例如 SCHOLARX_CACHE_MAX_AGE=72h、SCHOLARX_CACHE_MAX_MB=256
+	var cacheMaxAge time.Duration
+	if v := os.Getenv("SCHOLARX_CACHE_MAX_AGE"); v != "" {
+		if cacheMaxAge, err = time.ParseDuration(v); err != nil {
+			log.Fatal(err)
+		}
+	}
+	cacheMaxMB, _ := strconv.Atoi(os.Getenv("SCHOLARX_CACHE_MAX_MB"))
+	responseCache.SetDiskLimits(cacheMaxAge, int64(cacheMaxMB)<<20)
+	cacheTTLs := map[string]time.Duration{
+		"arxiv":    30 * time.Minute,
+		"openalex": time.Hour,
+		"dblp":     6 * time.Hour,
+		"s2":       time.Hour,
+	}
+	if spec := os.Getenv("SCHOLARX_CACHE_TTL"); spec != "" {
+		overrides, err := cache.ParseTTLs(spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for name, ttl := range overrides {
+			cacheTTLs[name] = ttl
+		}
+	}
+	for name, ttl := range cacheTTLs {
+		if p, ok := provider.Get(name); ok {
+			provider.Register(provider.WithCache(p, responseCache, ttl))
+		}
+	}
+	api.SetResponseCache(responseCache)
+	log.Printf("Local index loaded with %d papers", localIndex.Len())
+
+	// 后台任务：摄取新论文、刷新引用量、预计算每日摘要。
+	// 时间表可通过 SCHOLARX_SCHEDULE 覆盖，例如 "harvest=@every 2h;citations=off"
+	schedules := map[string]string{
+		"harvest":   "@every 1h",
+		"citations": "0 3 * * *",
+		"summaries": "*/20 * * * *",
+	}
+	if spec := os.Getenv("SCHOLARX_SCHEDULE"); spec != "" {
+		overrides, err := scheduler.ParseSchedules(spec)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for name, schedule := range overrides {
+			if _, ok := schedules[name]; !ok {
+				log.Fatalf("unknown job %q in SCHOLARX_SCHEDULE", name)
+			}
+			schedules[name] = schedule
+		}
+	}
+	jobs := scheduler.New(0)
+	for name, fn := range map[string]scheduler.JobFunc{
+		"harvest":   api.HarvestPapers,
+		"citations": api.RefreshCitations,
+		"summaries": api.PrecomputeSummaries,
+	} {
+		if err := jobs.Add(name, schedules[name], fn); err != nil {
+			log.Fatal(err)
+		}
+	}
+	jobs.Start()
+	api.SetScheduler(jobs)
+	api.SetAdminToken(os.Getenv("SCHOLARX_ADMIN_TOKEN"))
+
 	r := gin.Default()
 
 	// 静态文件
@@ -17,10 +160,49 @@ func main() {
 
 	// API 接口
 	r.GET("/search", api.SearchPapers)
+	r.GET("/search/stream", api.StreamSearch)
 	r.GET("/daily-summary", api.GetDailySummary)
+	r.GET("/daily-summary/history", api.GetSummaryHistory)
+	r.GET("/trends", api.GetTrends)
+	r.GET("/authors", api.SearchAuthors)
+	r.GET("/authors/:id", api.GetAuthor)
+	r.GET("/papers/*id", api.GetPaper)
+	r.GET("/export", api.ExportPapers)
+
+	// 管理接口：需设置 SCHOLARX_ADMIN_TOKEN 并携带 Authorization: Bearer <token>，未设置时返回 403
+	admin := r.Group("/admin", api.AdminAuth())
+	admin.GET("/jobs", api.ListJobs)
+	admin.POST("/jobs/:name/run", api.RunJob)
 
+	// 收到 SIGINT/SIGTERM 时优雅退出：停止接收新请求，等待进行中的请求与后台任务结束，再关闭本地论文库
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	srv := &http.Server{Addr: ":8000", Handler: r}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.ListenAndServe()
+	}()
 	log.Println("Server starting on http://localhost:8000")
-	if err := r.Run(":8000"); err != nil {
-		log.Fatal(err)
+
+	exitCode := 0
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			log.Println("server error:", err)
+			exitCode = 1
+		}
+	case <-ctx.Done():
+		log.Println("Shutting down...")
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		if err := srv.Shutdown(shutdownCtx); err != nil {
+			log.Println("server shutdown error:", err)
+		}
+		cancel()
+	}
+	stop()
+	jobs.Stop()
+	if err := paperStore.Close(); err != nil {
+		log.Println("store close error:", err)
+		exitCode = 1
 	}
+	os.Exit(exitCode)
 }
@@ -63,6 +63,7 @@ let activeCategory = "";
 let activeSort = "published_desc";
 let activeCCF = "";
 let currentTranslation = "";
+let failedSources = [];
 
 // 选择所有类名为 'source-checkbox' 的复选框
 const sourceCheckboxes = Array.from(
@@ -129,7 +130,14 @@ function sortPapers(items) {
   if (sortValue === "published_asc") {
     return [...items].sort((a, b) => new Date(a.published_at) - new Date(b.published_at));
   }
-  // 引用量排序已移除
+  if (sortValue === "citations") {
+    // 引用量由服务端经 Semantic Scholar 补全
+    return [...items].sort((a, b) => (b.citations || 0) - (a.citations || 0));
+  }
+  if (sortValue === "relevance") {
+    // 相关度由服务端计算，保持返回顺序
+    return [...items];
+  }
   if (sortValue === "title_asc") {
     return [...items].sort((a, b) => a.title.localeCompare(b.title));
   }
@@ -195,7 +203,12 @@ function updateStats(items) {
       prefix = `[翻译: ${currentTranslation}] `;
   }
   
-  statsText.textContent = `${prefix}${statsString ? `来源分布: ${statsString}` : "找到相关结果"}`;
+  let suffix = "";
+  if (failedSources.length > 0) {
+      suffix = ` · 以下来源暂不可用: ${failedSources.join(", ")}`;
+  }
+  
+  statsText.textContent = `${prefix}${statsString ? `来源分布: ${statsString}` : "找到相关结果"}${suffix}`;
 }
 
 function renderPapers(items) {
@@ -215,7 +228,8 @@ function renderPapers(items) {
     const card = node.querySelector(".card");
     
     // 头部信息
-    card.querySelector(".source-badge").textContent = paper.source || "unknown";
+    card.querySelector(".source-badge").textContent =
+      (paper.sources && paper.sources.length > 0 ? paper.sources.join(" + ") : paper.source) || "unknown";
     card.querySelector(".date-text").textContent = formatDate(paper.published_at);
     
     // CCF 标签
@@ -229,9 +243,10 @@ function renderPapers(items) {
 
     // 主要内容
     card.querySelector(".card-title").textContent = paper.title || "无标题";
+    const citationText = paper.citations > 0 ? ` · 引用 ${paper.citations}` : "";
     card.querySelector(".card-meta").textContent = `${formatAuthors(paper.authors)} · ${
       paper.venue || "未知来源"
-    }`;
+    }${citationText}`;
     
     card.querySelector(".card-abstract").textContent =
       paper.abstract || "暂无摘要信息";
@@ -258,7 +273,8 @@ function renderPapers(items) {
     paperList.appendChild(node);
   });
 
-  // 添加无限滚动哨兵
+  // 还有下一页时添加无限滚动哨兵
+  if (!hasMorePages()) return;
   const sentinel = document.createElement("div");
   sentinel.id = "scroll-sentinel";
   sentinel.style.height = "20px";
@@ -270,23 +286,52 @@ function renderPapers(items) {
   }
 }
 
-let currentOffset = 0;
+let currentCursor = ""; // 服务端返回的 next_cursor，为空表示没有更多结果
+let currentOffset = -1; // 服务端返回的 next_offset（排序只在每页内有效时使用偏移分页），-1 表示没有更多结果
+
+function hasMorePages() {
+  return currentCursor !== "" || currentOffset >= 0;
+}
 let isLoading = false;
 const LIMIT = 50;
 
 // 无限滚动观察器
 window.observer = new IntersectionObserver((entries) => {
-    if (entries[0].isIntersecting && !isLoading && allPapers.length > 0) {
+    if (entries[0].isIntersecting && !isLoading && hasMorePages()) {
         fetchPapers(true);
     }
 }, { rootMargin: "200px" });
 
+// 通过 /search/stream 获取第一页：每个数据源返回时先展示其结果，
+// done 事件给出去重合并后的最终结果
+function streamSearch(params) {
+  return new Promise((resolve, reject) => {
+    const stream = new EventSource(`/search/stream?${params.toString()}`);
+    let partial = [];
+    stream.addEventListener("source", (e) => {
+      const event = JSON.parse(e.data);
+      partial = [...partial, ...(event.items || [])];
+      allPapers = partial;
+      applyFilters();
+    });
+    stream.addEventListener("done", (e) => {
+      stream.close();
+      resolve(JSON.parse(e.data));
+    });
+    stream.onerror = () => {
+      stream.close();
+      reject(new Error("stream failed"));
+    };
+  });
+}
+
 async function fetchPapers(isAppend = false) {
   if (isLoading) return;
 
   if (!isAppend) {
       if (searchModal) searchModal.style.display = "block"; // Show modal for new searches
-      currentOffset = 0;
+      currentCursor = "";
+      currentOffset = -1;
       allPapers = [];
       paperList.innerHTML = `<div class="loading">正在加载数据...</div>`;
   } else {
@@ -312,37 +357,66 @@ async function fetchPapers(isAppend = false) {
   
   try {
     const params = buildQueryParams();
-    params.set("offset", currentOffset);
+    if (isAppend && currentCursor !== "") params.set("cursor", currentCursor);
+    else if (isAppend) params.set("offset", currentOffset);
     params.set("limit", LIMIT);
 
-    // 更正 API 端点以匹配 main.go
-    const response = await fetch(`/search?${params.toString()}`);
-    
-    if (!response.ok) {
-        throw new Error(`HTTP error! status: ${response.status}`);
+    let data = null;
+    if (!isAppend && window.EventSource) {
+        try {
+            data = await streamSearch(params);
+        } catch (streamError) {
+            console.warn("Stream error, falling back to /search:", streamError);
+        }
+        const sources = (data && data.sources) || [];
+        if (sources.length > 0 && sources.every((s) => s.status !== "ok" && !(s.fallback && s.count > 0))) {
+            // 所有请求的数据源都失败
+            throw new Error("all sources failed");
+        }
+    }
+
+    if (!data) {
+        // 更正 API 端点以匹配 main.go
+        const response = await fetch(`/search?${params.toString()}`);
+
+        if (response.status === 502) {
+            // 所有请求的数据源都失败
+            throw new Error("all sources failed");
+        }
+        if (!response.ok) {
+            throw new Error(`HTTP error! status: ${response.status}`);
+        }
+
+        data = await response.json();
     }
-    
-    const data = await response.json();
     const newItems = data.items || [];
     
     if (!isAppend) {
         currentTranslation = data.translation || "";
     }
+    currentCursor = data.next_cursor || "";
+    currentOffset = data.next_offset != null ? data.next_offset : -1;
+    failedSources = (data.sources || [])
+      .filter((s) => s.status !== "ok")
+      .map((s) => s.source);
     
     if (isAppend) {
          const loader = document.getElementById("loading-more-indicator");
          if (loader) loader.remove();
          
          if (newItems.length === 0) {
-             // 结果结束，如果存在则移除哨兵以停止触发
+             // 本页没有新结果：结果结束时移除哨兵，否则继续按游标加载
              const sentinel = document.getElementById("scroll-sentinel");
-             if (sentinel) sentinel.remove();
+             if (sentinel && !hasMorePages()) sentinel.remove();
+             if (sentinel && hasMorePages() && window.observer) {
+                 window.observer.unobserve(sentinel);
+                 window.observer.observe(sentinel);
+             }
              return; 
          }
     }
     
     allPapers = isAppend ? [...allPapers, ...newItems] : newItems;
-    currentOffset += LIMIT; // Advance offset
     
     // 基于所有加载的论文更新类别
     updateCategoryOptions();
@@ -351,7 +425,9 @@ async function fetchPapers(isAppend = false) {
   } catch (error) {
     console.error("Fetch error:", error);
     if (!isAppend) {
-        paperList.innerHTML = "<div class='empty-state'>加载失败，请检查网络或稍后再试</div>";
+        paperList.innerHTML = error.message === "all sources failed"
+          ? "<div class='empty-state'>所有数据源暂时不可用，请稍后再试</div>"
+          : "<div class='empty-state'>加载失败，请检查网络或稍后再试</div>";
     }
   } finally {
     isLoading = false;
@@ -368,7 +444,9 @@ async function fetchDailySummary() {
   if (!container) return;
 
   try {
-    const response = await fetch("/daily-summary");
+    // 页面地址中的 ?profile=systems 透传给接口，选择对应的摘要范围
+    const profile = new URLSearchParams(window.location.search).get("profile");
+    const response = await fetch(profile ? `/daily-summary?profile=${encodeURIComponent(profile)}` : "/daily-summary");
     if (!response.ok) throw new Error("Summary fetch failed");
     
     const summary = await response.json();
@@ -409,6 +487,20 @@ function renderDailySummary(summary) {
     </span>
   `).join("");
 
+  // 相对此前 30 天基线上升的主题（需要服务端启用本地论文库）
+  let risingHtml = ((summary.trends && summary.trends.rising) || []).map(t => `
+    <span class="topic-tag clickable-topic" onclick="window.searchByTopic('${t.topic.replace(/'/g, "\\'")}')" title="z = ${t.z_score}，点击搜索此主题">
+        ${t.topic}<span class="count">${t.growth >= 10 ? "新" : "+" + Math.round(t.growth * 100) + "%"}</span>
+    </span>
+  `).join("");
+
+  // 论文数最多的机构，重点机构以 ★ 标记
+  let institutionsHtml = (summary.institutions || []).map(i => `
+    <span class="topic-tag" title="${i.country || ""}">
+        ${i.notable ? "★ " : ""}${i.name}<span class="count">${i.count}</span>
+    </span>
+  `).join("");
+
   let breakthroughsHtml = (summary.breakthroughs || []).map(p => `
     <div class="highlight-card">
       <div class="highlight-title">${p.title}</div>
@@ -480,6 +572,33 @@ function renderDailySummary(summary) {
             ${topicsHtml}
           </div>
         </div>
+        ${risingHtml ? `
+        <div class="summary-section" style="margin-top: 24px;">
+          <h3>
+            <svg width="18" height="18" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
+              <polyline points="23 6 13.5 15.5 8.5 10.5 1 18"></polyline>
+              <polyline points="17 6 23 6 23 12"></polyline>
+            </svg>
+            上升主题
+          </h3>
+          <div class="topic-tags">
+            ${risingHtml}
+          </div>
+        </div>` : ""}
+        ${institutionsHtml ? `
+        <div class="summary-section" style="margin-top: 24px;">
+          <h3>
+            <svg width="18" height="18" viewBox="0 0 24 24" fill="none" stroke="currentColor" stroke-width="2">
+              <path d="M3 21h18"></path>
+              <path d="M5 21V10l7-5 7 5v11"></path>
+              <path d="M9 21v-6h6v6"></path>
+            </svg>
+            活跃机构
+          </h3>
+          <div class="topic-tags">
+            ${institutionsHtml}
+          </div>
+        </div>` : ""}
       </div>
     </div>
   `;
@@ -487,6 +606,18 @@ function renderDailySummary(summary) {
   container.innerHTML = html;
 }
 
+// 导出：使用与当前搜索相同的参数
+const exportBtn = document.getElementById("exportBtn");
+const exportFormat = document.getElementById("exportFormat");
+if (exportBtn) {
+  exportBtn.addEventListener("click", () => {
+    const params = buildQueryParams();
+    params.set("format", exportFormat.value);
+    params.set("limit", Math.max(allPapers.length, LIMIT));
+    window.open(`/export?${params.toString()}`, "_blank");
+  });
+}
+
 // Event Listeners
 refreshBtn.addEventListener("click", () => fetchPapers(false));
 queryInput.addEventListener("keypress", (e) => {